@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -12,17 +13,23 @@ import (
 
 	"padaroja/internal/handlers/admin"
 	"padaroja/internal/handlers/auth"
+	"padaroja/internal/handlers/collection"
 	"padaroja/internal/handlers/comment" // ДОБАВИТЬ ЭТОТ ИМПОРТ
 	"padaroja/internal/handlers/favourite"
 	"padaroja/internal/handlers/follows"
 	"padaroja/internal/handlers/like"
 	maps "padaroja/internal/handlers/map"
 	"padaroja/internal/handlers/moderation"
+	"padaroja/internal/handlers/notification"
 	"padaroja/internal/handlers/post"
 	"padaroja/internal/handlers/profile"
+	"padaroja/internal/handlers/review"
+	"padaroja/internal/handlers/tag"
+	"padaroja/internal/handlers/upload"
 	"padaroja/internal/middleware"
 	"padaroja/internal/sse"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/internal/storage/uploads"
 	utils "padaroja/utils/auth"
 )
 
@@ -43,6 +50,11 @@ func main() {
 	}
 
 	database.ConnectDB()
+	tag.NormalizeExistingTags()
+
+	if err := uploads.Init(); err != nil {
+		log.Fatalf("Failed to initialize uploads storage: %v", err)
+	}
 
 	if env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -52,6 +64,39 @@ func main() {
 	hub := sse.NewHub()
 	go hub.Run()
 
+	publishInterval := time.Minute
+	if v := os.Getenv("PUBLISHER_INTERVAL_SECONDS"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+			publishInterval = time.Duration(seconds) * time.Second
+		}
+	}
+	go post.RunScheduledPublisher(publishInterval)
+
+	viewFlushInterval := 30 * time.Second
+	if v := os.Getenv("VIEW_FLUSH_INTERVAL_SECONDS"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+			viewFlushInterval = time.Duration(seconds) * time.Second
+		}
+	}
+	go post.RunViewAggregator(viewFlushInterval)
+	go post.RunTrashPurger(time.Hour)
+
+	hotScoreInterval := 5 * time.Minute
+	if v := os.Getenv("HOT_SCORE_INTERVAL_SECONDS"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+			hotScoreInterval = time.Duration(seconds) * time.Second
+		}
+	}
+	go post.RunHotScoreUpdater(hotScoreInterval)
+
+	go post.BackfillPostSlugs()
+	// Отпечатки для проверки на дубли считаются по уже заполненному числу слов
+	go func() {
+		post.BackfillPostPreviews()
+		post.BackfillPostFingerprints()
+	}()
+	go post.BackfillPostSearchDocuments()
+
 	frontendURL := os.Getenv("FRONTEND_URL")
 	if frontendURL == "" {
 		frontendURL = "http://localhost:3000"
@@ -82,6 +127,13 @@ func main() {
 
 	api := router.Group("/api")
 
+	// Загруженные файлы отдаются под /api, т.к. прокси пробрасывает в backend только /api/
+	api.Static("/uploads", uploads.Root)
+	uploadRoutes := api.Group("/uploads")
+	{
+		uploadRoutes.POST("/photos", middleware.AuthMiddleware(), upload.UploadPhoto)
+	}
+
 	authRoutes := api.Group("/auth")
 	{
 		authRoutes.POST("/register", auth.Register)
@@ -93,6 +145,7 @@ func main() {
 	{
 		userRoutes.GET("/:userID/profile", middleware.OptionalAuthMiddleware(), profile.GetUserProfileByID)
 		userRoutes.GET("/:userID/posts", middleware.OptionalAuthMiddleware(), post.GetUserPostsByID)
+		userRoutes.GET("/:userID/collections", middleware.OptionalAuthMiddleware(), collection.GetUserCollections)
 		userRoutes.GET("/search", middleware.OptionalAuthMiddleware(), profile.SearchUsers)
 		userRoutes.GET("/search/invite", middleware.AuthMiddleware(), profile.SearchUsersForInvite)
 
@@ -101,7 +154,14 @@ func main() {
 		{
 			protectedUserRoutes.GET("/profile", profile.GetCurrentUserProfile)
 			protectedUserRoutes.PUT("/profile", profile.UpdateUserProfile)
+			protectedUserRoutes.POST("/profile/avatar", upload.UploadAvatar)
 			protectedUserRoutes.GET("/posts", post.GetUserPosts)
+			protectedUserRoutes.GET("/analytics", post.GetPostsAnalytics)
+			protectedUserRoutes.GET("/trash", post.GetTrash)
+			protectedUserRoutes.POST("/trash/:postID/restore", post.RestorePost)
+			protectedUserRoutes.DELETE("/trash/:postID", post.PurgeTrashedPost)
+			protectedUserRoutes.GET("/export", post.ExportUserArchive)
+			protectedUserRoutes.POST("/import", post.ImportUserArchive)
 			protectedUserRoutes.POST("/:userID/follow", follows.FollowUser)
 			protectedUserRoutes.DELETE("/:userID/follow", follows.UnfollowUser)
 			protectedUserRoutes.GET("/:userID/follow/check", follows.CheckFollow)
@@ -146,14 +206,28 @@ func main() {
 		// Основные маршруты
 		postRoutes.GET("", middleware.OptionalAuthMiddleware(), post.GetPublicFeed)
 		postRoutes.GET("/:postID", middleware.OptionalAuthMiddleware(), post.GetPost)
+		postRoutes.GET("/slug/:username/:slug", middleware.OptionalAuthMiddleware(), post.GetPostBySlug)
 		postRoutes.GET("/:postID/collaborators/check", middleware.AuthMiddleware(), post.CheckCollaboratorStatus)
 		postRoutes.POST("", middleware.AuthMiddleware(), post.CreatePost)
+		postRoutes.GET("/search", post.SearchPosts)
 		postRoutes.GET("/search/settlements", post.SearchSettlements)
+		postRoutes.GET("/search/settlements/nearby", middleware.AuthMiddleware(), post.SuggestSettlements)
 		postRoutes.PUT("/:postID", middleware.AuthMiddleware(), post.UpdatePost)
 		postRoutes.DELETE("/:postID", middleware.AuthMiddleware(), post.DeletePost)
 		postRoutes.POST("/:postID/report", middleware.AuthMiddleware(), post.ReportPost)
 		postRoutes.PATCH("/:postID/comments", middleware.AuthMiddleware(), post.ToggleComments)
 
+		// История правок поста
+		postRoutes.GET("/:postID/revisions", middleware.AuthMiddleware(), post.GetPostRevisions)
+		postRoutes.GET("/:postID/revisions/diff", middleware.AuthMiddleware(), post.GetPostRevisionDiff)
+		postRoutes.GET("/:postID/revisions/:version", middleware.AuthMiddleware(), post.GetPostRevision)
+		postRoutes.POST("/:postID/revisions/:version/restore", middleware.AuthMiddleware(), post.RestorePostRevision)
+
+		// Переводы поста (ru, be, en)
+		postRoutes.GET("/:postID/translations", middleware.OptionalAuthMiddleware(), post.GetPostTranslations)
+		postRoutes.PUT("/:postID/translations/:lang", middleware.AuthMiddleware(), post.UpsertPostTranslation)
+		postRoutes.DELETE("/:postID/translations/:lang", middleware.AuthMiddleware(), post.DeletePostTranslation)
+
 		// Маршруты для приглашений
 		postRoutes.GET("/invites/pending", middleware.AuthMiddleware(), post.GetPendingInvites)
 		postRoutes.PUT("/invites/:inviteID/accept", middleware.AuthMiddleware(), post.AcceptInvite)
@@ -168,17 +242,65 @@ func main() {
 		postRoutes.POST("/:postID/leave", middleware.AuthMiddleware(), post.LeaveCollaboration)
 	}
 
+	collectionRoutes := api.Group("/collections")
+	{
+		collectionRoutes.GET("", middleware.AuthMiddleware(), collection.GetMyCollections)
+		collectionRoutes.POST("", middleware.AuthMiddleware(), collection.CreateCollection)
+		collectionRoutes.GET("/:collectionID", middleware.OptionalAuthMiddleware(), collection.GetCollection)
+		collectionRoutes.PUT("/:collectionID", middleware.AuthMiddleware(), collection.UpdateCollection)
+		collectionRoutes.DELETE("/:collectionID", middleware.AuthMiddleware(), collection.DeleteCollection)
+
+		// Посты подборки
+		collectionRoutes.GET("/:collectionID/posts", middleware.OptionalAuthMiddleware(), collection.GetCollectionPosts)
+		collectionRoutes.POST("/:collectionID/posts", middleware.AuthMiddleware(), collection.AddPostToCollection)
+		collectionRoutes.PUT("/:collectionID/posts/order", middleware.AuthMiddleware(), collection.ReorderCollection)
+		collectionRoutes.DELETE("/:collectionID/posts/:postID", middleware.AuthMiddleware(), collection.RemovePostFromCollection)
+
+		// Соавторы подборки
+		collectionRoutes.POST("/:collectionID/curators", middleware.AuthMiddleware(), collection.AddCurator)
+		collectionRoutes.DELETE("/:collectionID/curators/:userID", middleware.AuthMiddleware(), collection.RemoveCurator)
+	}
+
 	mapRoutes := api.Group("/map")
 	{
 		mapRoutes.GET("/user/:userID/data", maps.GetMapDataByUserID)
+		mapRoutes.GET("/user/:userID/timeline", maps.GetUserTimeline)
 		mapRoutes.GET("/user-data", middleware.AuthMiddleware(), maps.GetUserMapData)
 		mapRoutes.GET("/posts/all", maps.GetAllPostsMapData)
+		mapRoutes.GET("/collections/:collectionID", middleware.OptionalAuthMiddleware(), maps.GetCollectionMapData)
+	}
+
+	settlementRoutes := api.Group("/settlements")
+	{
+		settlementRoutes.GET("/top", review.GetTopSettlements)
+		settlementRoutes.GET("/:settlementID/reviews", middleware.OptionalAuthMiddleware(), review.GetSettlementReviews)
+		settlementRoutes.PUT("/:settlementID/reviews", middleware.AuthMiddleware(), review.UpsertReview)
+		settlementRoutes.DELETE("/:settlementID/reviews", middleware.AuthMiddleware(), review.DeleteReview)
+	}
+
+	savedSearchRoutes := api.Group("/saved-searches")
+	{
+		savedSearchRoutes.Use(middleware.AuthMiddleware())
+		savedSearchRoutes.GET("", post.GetSavedSearches)
+		savedSearchRoutes.POST("", post.CreateSavedSearch)
+		savedSearchRoutes.PUT("/:searchID", post.UpdateSavedSearch)
+		savedSearchRoutes.DELETE("/:searchID", post.DeleteSavedSearch)
+	}
+
+	notificationRoutes := api.Group("/notifications")
+	{
+		notificationRoutes.Use(middleware.AuthMiddleware())
+		notificationRoutes.GET("", notification.GetNotifications)
+		notificationRoutes.GET("/stream", notification.StreamNotifications)
+		notificationRoutes.PUT("/read", notification.MarkAllNotificationsRead)
+		notificationRoutes.PUT("/:notificationID/read", notification.MarkNotificationRead)
 	}
 
 	recommendationsRoutes := api.Group("/recommendations")
 	{
 		recommendationsRoutes.GET("/geo", middleware.AuthMiddleware(), post.GetGeoRecommendations)
 		recommendationsRoutes.GET("/follow", middleware.AuthMiddleware(), post.GetFollowRecommendations)
+		recommendationsRoutes.GET("/for-you", middleware.AuthMiddleware(), post.GetForYouRecommendations)
 	}
 
 	modRoutes := api.Group("/mod")
@@ -187,6 +309,7 @@ func main() {
 
 		modRoutes.GET("/complaints", moderation.GetComplaints)
 		modRoutes.PUT("/complaints/:complaintID/status", moderation.UpdateComplaintStatus)
+		modRoutes.PUT("/duplicates/:flagID/status", moderation.UpdateDuplicateFlagStatus)
 
 		modRoutes.POST("/posts/:postID/complaint", moderation.CreatePostComplaint)
 		modRoutes.PUT("/posts/:postID/visibility", moderation.TogglePostVisibility)
@@ -196,6 +319,10 @@ func main() {
 		modRoutes.PUT("/comments/:commentID/visibility", moderation.ToggleCommentVisibility)
 		modRoutes.GET("/comments/:commentID/complaints", moderation.GetCommentComplaints)
 
+		modRoutes.POST("/reviews/:reviewID/complaint", moderation.CreateReviewComplaint)
+		modRoutes.PUT("/reviews/:reviewID/visibility", moderation.ToggleReviewVisibility)
+		modRoutes.GET("/reviews/:reviewID/complaints", moderation.GetReviewComplaints)
+
 		modRoutes.GET("/users-with-complaints", moderation.GetUsersWithComplaints)
 		modRoutes.POST("/users/:userID/block", moderation.BlockUser)
 		modRoutes.POST("/users/:userID/unblock", moderation.UnblockUser)
@@ -223,6 +350,12 @@ func main() {
 		favouriteRoutes.GET("/check-multiple", middleware.AuthMiddleware(), favourite.CheckMultipleFavourites)
 	}
 
+	tagRoutes := api.Group("/tags")
+	{
+		tagRoutes.GET("/autocomplete", tag.AutocompleteTags)
+		tagRoutes.GET("/:name", tag.GetTag)
+	}
+
 	adminRoutes := api.Group("/admin")
 	adminRoutes.Use(middleware.AuthMiddleware())
 	{
@@ -234,6 +367,12 @@ func main() {
 		adminRoutes.POST("/users/:userID/remove-moderator", admin.RemoveModeratorByAdmin)
 		adminRoutes.POST("/users/:userID/block", admin.BlockUserByAdmin)
 		adminRoutes.POST("/users/:userID/unblock", admin.UnblockUserByAdmin)
+
+		// Управление тегами: синонимы и слияние
+		adminRoutes.GET("/tags", tag.GetTagsForAdmin)
+		adminRoutes.POST("/tags/:tagID/synonyms", tag.AddTagSynonym)
+		adminRoutes.DELETE("/tags/synonyms/:synonymID", tag.DeleteTagSynonym)
+		adminRoutes.POST("/tags/:tagID/merge", tag.MergeTagsIntoTag)
 	}
 
 	log.Printf("Сервер запущен на порту %s в режиме %s", serverPort, env)
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// Типы блоков содержимого поста
+const (
+	BlockText    = "text"
+	BlockHeading = "heading"
+	BlockQuote   = "quote"
+	BlockPhoto   = "photo"
+	BlockMapPin  = "map_pin"
+	BlockTip     = "tip"
+)
+
+// BlockData - JSON-данные блока (уровень заголовка, координаты метки и т.п.), в БД хранятся как jsonb
+type BlockData []byte
+
+func (d BlockData) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return string(d), nil
+}
+
+func (d *BlockData) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+	case []byte:
+		*d = append((*d)[:0], v...)
+	case string:
+		*d = BlockData(v)
+	default:
+		return errors.New("unsupported type for BlockData")
+	}
+	return nil
+}
+
+func (d BlockData) MarshalJSON() ([]byte, error) {
+	if len(d) == 0 {
+		return []byte("null"), nil
+	}
+	return d, nil
+}
+
+func (d *BlockData) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = nil
+		return nil
+	}
+	*d = append((*d)[:0], data...)
+	return nil
+}
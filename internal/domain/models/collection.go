@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Collection - подборка постов, составленная пользователем ("Замки Гродненщины", "Лето 2025")
+type Collection struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      int       `gorm:"not null;index" json:"user_id"`
+	Title       string    `gorm:"size:200;not null" json:"title"`
+	Description string    `gorm:"type:text" json:"description"`
+	CoverUrl    string    `json:"cover_url"`
+	IsPublic    bool      `gorm:"default:false;index" json:"is_public"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	User     User                `gorm:"foreignKey:UserID" json:"user"`
+	Items    []CollectionItem    `gorm:"foreignKey:CollectionID" json:"items,omitempty"`
+	Curators []CollectionCurator `gorm:"foreignKey:CollectionID" json:"curators,omitempty"`
+}
+
+// CollectionItem - пост в подборке; Position задаёт порядок
+type CollectionItem struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CollectionID uint      `gorm:"not null;uniqueIndex:idx_collection_post;constraint:OnDelete:CASCADE;" json:"collection_id"`
+	PostID       uint      `gorm:"not null;uniqueIndex:idx_collection_post;index" json:"post_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	AddedBy      int       `gorm:"not null" json:"added_by"`
+	AddedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"added_at"`
+
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
+
+// CollectionCurator - соавтор подборки: может добавлять, убирать и переставлять посты
+type CollectionCurator struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CollectionID uint      `gorm:"not null;uniqueIndex:idx_collection_curator;constraint:OnDelete:CASCADE;" json:"collection_id"`
+	UserID       int       `gorm:"not null;uniqueIndex:idx_collection_curator;index" json:"user_id"`
+	AddedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"added_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"user"`
+}
@@ -12,6 +12,7 @@ type ComplaintType string
 const (
 	ComplaintTypePost    ComplaintType = "POST"
 	ComplaintTypeComment ComplaintType = "COMMENT"
+	ComplaintTypeReview  ComplaintType = "REVIEW"
 )
 
 type ComplaintStatus string
@@ -33,6 +34,7 @@ type Complaint struct {
 	Type      ComplaintType   `gorm:"type:varchar(20);not null;default:'POST'" json:"type"`
 	PostID    *uint           `gorm:"constraint:OnDelete:CASCADE;" json:"post_id,omitempty"`
 	CommentID *uint           `gorm:"constraint:OnDelete:CASCADE;" json:"comment_id,omitempty"`
+	ReviewID  *uint           `gorm:"index" json:"review_id,omitempty"`
 	Reason    string          `gorm:"type:text;not null" json:"reason"`
 	Status    ComplaintStatus `gorm:"type:varchar(20);default:'NEW'" json:"status"`
 
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostFingerprint - MinHash-подпись текста поста для поиска почти одинаковых постов
+type PostFingerprint struct {
+	PostID    uint      `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	UserID    int       `gorm:"not null;index" json:"user_id"`
+	Signature string    `gorm:"type:text;not null" json:"-"` // JSON-массив значений подписи
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Post Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// PostFingerprintBand - хеш части подписи; посты с совпавшей частью сравниваются целиком
+type PostFingerprintBand struct {
+	PostID uint  `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	Band   int   `gorm:"primaryKey;autoIncrement:false;index:idx_fingerprint_band_hash,priority:1" json:"band"`
+	Hash   int64 `gorm:"not null;index:idx_fingerprint_band_hash,priority:2" json:"hash"`
+}
+
+// DuplicateFlag - автоматическая отметка модераторам: текст поста почти совпадает с постами других авторов.
+// Разбирается в общей очереди жалоб, статусы те же, что у Complaint.
+type DuplicateFlag struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	PostID         uint            `gorm:"not null;uniqueIndex" json:"post_id"`
+	MatchedPostIDs string          `gorm:"type:text;not null" json:"-"` // JSON-массив ID более ранних постов с тем же текстом
+	Similarity     float64         `gorm:"not null" json:"similarity"`  // наибольшее сходство, 0..1
+	Status         ComplaintStatus `gorm:"type:varchar(20);default:'NEW'" json:"status"`
+
+	Post Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"-"`
+}
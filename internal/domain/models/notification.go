@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Типы уведомлений
+const (
+	NotificationSavedSearch = "SAVED_SEARCH_MATCH" // новый пост подходит под сохранённый поиск
+)
+
+// SavedSearch - сохранённый запрос ленты; о новых подходящих постах пользователь получает уведомления.
+// Пустые поля не ограничивают выборку; радиус задаётся вместе с точкой.
+type SavedSearch struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int       `gorm:"not null;index" json:"user_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Query      string    `gorm:"size:200;not null;default:''" json:"query"`
+	Tags       string    `gorm:"size:500;not null;default:''" json:"tags"` // через запятую, как параметр tags ленты
+	TagsAny    bool      `gorm:"not null;default:false" json:"tags_any"`
+	Admin1Code string    `gorm:"size:20;not null;default:''" json:"admin1_code"`
+	Admin2Code string    `gorm:"size:80;not null;default:''" json:"admin2_code"`
+	Latitude   *float64  `json:"latitude"`
+	Longitude  *float64  `json:"longitude"`
+	RadiusKm   float64   `gorm:"not null;default:0" json:"radius_km"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// Notification - сохранённое уведомление пользователя; приходит и по SSE, и видно после входа
+type Notification struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int       `gorm:"not null;index:idx_notifications_user,priority:1" json:"user_id"`
+	Type          string    `gorm:"size:50;not null" json:"type"`
+	PostID        *uint     `gorm:"index" json:"post_id,omitempty"`
+	SavedSearchID *uint     `gorm:"index" json:"saved_search_id,omitempty"`
+	Text          string    `gorm:"size:500;not null" json:"text"`
+	IsRead        bool      `gorm:"not null;default:false" json:"is_read"`
+	CreatedAt     time.Time `gorm:"index:idx_notifications_user,priority:2" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
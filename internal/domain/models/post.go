@@ -1,6 +1,18 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+)
 
 type Post struct {
 	ID               uint      `gorm:"primaryKey" json:"id"`
@@ -12,13 +24,44 @@ type Post struct {
 	IsApproved       bool      `gorm:"default:false" json:"is_approved"`
 	CreatedAt        time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	LikesCount       int       `gorm:"default:0" json:"likes_count"`
+	ViewsCount       int       `gorm:"default:0" json:"views_count"`              // обновляется агрегатором просмотров
+	HotScore         int64     `gorm:"not null;default:0;index" json:"hot_score"` // оценка актуальности ×1e6, пересчитывается в фоне
 	CommentsDisabled bool      `gorm:"default:false" json:"comments_disabled"`
 
+	// Жизненный цикл поста: черновик, отложенная публикация или опубликован
+	Status    PostStatus `gorm:"type:varchar(20);not null;default:'published';index" json:"status"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	// Номер версии для оптимистичной блокировки при совместном редактировании
+	Version int `gorm:"not null;default:1" json:"version"`
+
+	// Даты самой поездки (CreatedAt - когда написан пост); VisitedTo пуст для однодневной поездки
+	VisitedFrom *time.Time `gorm:"type:date;index" json:"visited_from,omitempty"`
+	VisitedTo   *time.Time `gorm:"type:date" json:"visited_to,omitempty"`
+
+	// Общая длина маршрута по остановкам поездки, км
+	RouteDistanceKm float64 `gorm:"default:0" json:"route_distance_km"`
+
+	// Язык оригинала (ru, be, en); переводы хранятся в PostTranslation
+	Language string `gorm:"size:10;not null;default:'ru'" json:"language"`
+
+	// Время удаления в корзину; такой пост скрыт из всех выборок и окончательно удаляется после срока хранения
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Отрывок текста для карточек ленты, число слов и время чтения; пересчитываются при сохранении поста
+	PreviewText        string `gorm:"size:400;not null;default:''" json:"preview_text"`
+	WordCount          int    `gorm:"not null;default:0" json:"word_count"`
+	ReadingTimeMinutes int    `gorm:"not null;default:0" json:"reading_time_minutes"`
+
+	// Транслитерированный заголовок для адреса поста, уникален среди постов автора
+	Slug string `gorm:"size:100;index" json:"slug"`
+
 	Settlement Settlement  `gorm:"foreignKey:SettlementID;references:Geonameid" json:"settlement"`
 	Paragraphs []Paragraph `gorm:"foreignKey:PostID" json:"paragraphs"`
 	Photos     []PostPhoto `gorm:"foreignKey:PostID" json:"photos"`
 	Comments   []Comment   `gorm:"foreignKey:PostID" json:"comments,omitempty"`
 	Tags       []Tags      `gorm:"many2many:post_tags;" json:"tags,omitempty"`
+	Stops      []TripStop  `gorm:"foreignKey:PostID" json:"stops,omitempty"`
 }
 
 type Paragraph struct {
@@ -26,6 +69,12 @@ type Paragraph struct {
 	PostID  uint   `gorm:"not null" json:"post_id"`
 	Order   int    `gorm:"not null" json:"order"`
 	Content string `gorm:"type:text;not null" json:"content"`
+
+	// Тип блока (text, heading, quote, photo, map_pin, tip) и его данные; старые параграфы - text
+	Type string    `gorm:"size:20;not null;default:'text'" json:"type"`
+	Data BlockData `gorm:"type:jsonb" json:"data,omitempty"`
+
+	StopOrder *int `json:"stop_order,omitempty"` // остановка поездки, к которой относится параграф
 }
 
 type PostPhoto struct {
@@ -34,6 +83,25 @@ type PostPhoto struct {
 	Url        string `gorm:"not null" json:"url"`
 	Order      int    `json:"order"`
 	IsApproved bool   `gorm:"default:true" json:"is_approved"`
+	StopOrder  *int   `json:"stop_order,omitempty"` // остановка поездки, к которой относится фото
+
+	// Заполняются сервером по записи Upload, если фото загружено через /api/uploads
+	Width           int    `gorm:"default:0" json:"width,omitempty"`
+	Height          int    `gorm:"default:0" json:"height,omitempty"`
+	ThumbnailSmall  string `json:"thumbnail_small,omitempty"`
+	ThumbnailMedium string `json:"thumbnail_medium,omitempty"`
+	ThumbnailLarge  string `json:"thumbnail_large,omitempty"`
+}
+
+// TripStop - остановка многодневной поездки, привязанная к населённому пункту GeoNames
+type TripStop struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID         uint   `gorm:"not null;index;constraint:OnDelete:CASCADE;" json:"post_id"`
+	Order          int    `gorm:"not null" json:"order"`
+	SettlementID   uint   `gorm:"not null" json:"settlement_id"`
+	SettlementName string `gorm:"size:200;not null" json:"settlement_name"`
+
+	Settlement Settlement `gorm:"foreignKey:SettlementID;references:Geonameid" json:"settlement"`
 }
 
 type Settlement struct {
@@ -41,12 +109,16 @@ type Settlement struct {
 	Name           string  `gorm:"column:name;type:text" json:"name"`
 	Asciiname      string  `gorm:"column:asciiname;type:text" json:"asciiname"`
 	Alternatenames string  `gorm:"column:alternatenames;type:text" json:"alternatenames"`
-	Latitude       float64 `gorm:"column:latitude;type:double precision" json:"latitude"`
-	Longitude      float64 `gorm:"column:longitude;type:double precision" json:"longitude"`
+	Latitude       float64 `gorm:"column:latitude;type:double precision;index:idx_settlements_location" json:"latitude"`
+	Longitude      float64 `gorm:"column:longitude;type:double precision;index:idx_settlements_location" json:"longitude"`
 	FeatureClass   string  `gorm:"column:feature_class;type:text" json:"feature_class"`
 	FeatureCode    string  `gorm:"column:feature_code;type:text" json:"feature_code"`
-	Admin1Code     string  `gorm:"column:admin1_code;type:text" json:"admin1_code"`
-	Admin2Code     string  `gorm:"column:admin2_code;type:text" json:"admin2_code"`
+	Admin1Code     string  `gorm:"column:admin1_code;type:text;index:idx_settlements_admin" json:"admin1_code"`
+	Admin2Code     string  `gorm:"column:admin2_code;type:text;index:idx_settlements_admin" json:"admin2_code"`
+
+	// Сводка одобренных отзывов; пересчитывается при каждом изменении отзыва
+	ReviewsCount  int     `gorm:"column:reviews_count;not null;default:0" json:"reviews_count"`
+	AverageRating float64 `gorm:"column:average_rating;not null;default:0" json:"average_rating"`
 }
 
 type PostTag struct {
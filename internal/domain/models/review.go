@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Review - отзыв пользователя о населённом пункте: оценка 1-5 и необязательный короткий текст.
+// У пользователя один отзыв на населённый пункт; пост, связанный с отзывом, указывать не обязательно.
+type Review struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SettlementID uint      `gorm:"not null;uniqueIndex:idx_review_user_settlement;index" json:"settlement_id"`
+	UserID       int       `gorm:"not null;uniqueIndex:idx_review_user_settlement" json:"user_id"`
+	PostID       *uint     `gorm:"index" json:"post_id,omitempty"`
+	Rating       int       `gorm:"not null" json:"rating"`
+	Text         string    `gorm:"size:1000;not null;default:''" json:"text"`
+	IsApproved   bool      `gorm:"default:true" json:"is_approved"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	User       User       `gorm:"foreignKey:UserID" json:"user"`
+	Settlement Settlement `gorm:"foreignKey:SettlementID;references:Geonameid" json:"-"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PostRevision - снимок поста (заголовок, населённый пункт, параграфы, фото и теги) после сохранения
+type PostRevision struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID         uint      `gorm:"not null;uniqueIndex:idx_post_revision_version;constraint:OnDelete:CASCADE;" json:"post_id"`
+	Version        int       `gorm:"not null;uniqueIndex:idx_post_revision_version" json:"version"`
+	EditorID       int       `gorm:"not null" json:"editor_id"`
+	Title          string    `gorm:"size:200;not null" json:"title"`
+	SettlementID   uint      `json:"settlement_id"`
+	SettlementName string    `gorm:"size:200" json:"settlement_name"`
+	Paragraphs     string    `gorm:"type:text" json:"-"` // JSON-массив параграфов
+	Photos         string    `gorm:"type:text" json:"-"` // JSON-массив фото
+	Tags           string    `gorm:"type:text" json:"-"` // JSON-массив названий тегов
+	Stops          string    `gorm:"type:text" json:"-"` // JSON-массив остановок поездки
+	RestoredFrom   *int      `json:"restored_from,omitempty"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Post   Post `gorm:"foreignKey:PostID" json:"-"`
+	Editor User `gorm:"foreignKey:EditorID" json:"-"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PostSearchDocument - поисковый документ поста для полнотекстового поиска.
+// Собирается из заголовка, мест, тегов и текста поста вместе с переводами.
+type PostSearchDocument struct {
+	PostID    uint      `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	Content   string    `gorm:"type:text;not null;default:''" json:"-"`                                  // текст без разметки, из него берутся отрывки с подсветкой
+	Document  string    `gorm:"type:tsvector;not null;index:idx_post_search_document,type:gin" json:"-"` // русская и простая конфигурации вместе
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Post Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"-"`
+}
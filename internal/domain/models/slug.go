@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PostSlugHistory - прежние slug поста; по ним старые ссылки перенаправляются на текущий адрес
+type PostSlugHistory struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID    uint      `gorm:"not null;index;constraint:OnDelete:CASCADE;" json:"post_id"`
+	UserID    int       `gorm:"not null;uniqueIndex:idx_post_slug_history_user_slug" json:"user_id"`
+	Slug      string    `gorm:"size:100;not null;uniqueIndex:idx_post_slug_history_user_slug" json:"slug"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
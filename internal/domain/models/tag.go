@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TagSynonym - альтернативное написание тега ("zamok" -> "замок"); при сохранении поста заменяется основным тегом
+type TagSynonym struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"size:150;not null;uniqueIndex" json:"name"` // нормализованное название
+	TagID     uint      `gorm:"not null;index" json:"tag_id"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Tag Tags `gorm:"foreignKey:TagID" json:"-"`
+}
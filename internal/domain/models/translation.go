@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// PostTranslation - перевод заголовка и параграфов поста на другой язык; оригинал хранится в самом посте
+type PostTranslation struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID     uint      `gorm:"not null;uniqueIndex:idx_post_translation_language;constraint:OnDelete:CASCADE;" json:"post_id"`
+	Language   string    `gorm:"size:10;not null;uniqueIndex:idx_post_translation_language" json:"language"`
+	Title      string    `gorm:"size:200;not null" json:"title"`
+	Paragraphs string    `gorm:"type:text" json:"-"` // JSON-массив параграфов
+	UpdatedBy  int       `gorm:"not null" json:"updated_by"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Отрывок, число слов и время чтения перевода - как у поста
+	PreviewText        string `gorm:"size:400;not null;default:''" json:"preview_text"`
+	WordCount          int    `gorm:"not null;default:0" json:"word_count"`
+	ReadingTimeMinutes int    `gorm:"not null;default:0" json:"reading_time_minutes"`
+
+	Post Post `gorm:"foreignKey:PostID" json:"-"`
+}
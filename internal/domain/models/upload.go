@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Upload - загруженное изображение; одинаковые файлы хранятся один раз (дедупликация по хэшу)
+type Upload struct {
+	ID              uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Hash            string `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+	UserID          int    `gorm:"not null;index" json:"user_id"`
+	MimeType        string `gorm:"size:50;not null" json:"mime_type"`
+	Size            int64  `gorm:"not null" json:"size"`
+	Width           int    `gorm:"not null" json:"width"`
+	Height          int    `gorm:"not null" json:"height"`
+	Url             string `gorm:"not null;index" json:"url"`
+	ThumbnailSmall  string `json:"thumbnail_small"`
+	ThumbnailMedium string `json:"thumbnail_medium"`
+	ThumbnailLarge  string `json:"thumbnail_large"`
+
+	// Координаты съёмки из EXIF - только для подсказки населённого пункта, публично не отдаются
+	Latitude  *float64 `json:"-"`
+	Longitude *float64 `json:"-"`
+
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PostView - просмотр поста читателем; один читатель в одном окне дедупликации считается один раз
+type PostView struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID      uint      `gorm:"not null;uniqueIndex:idx_post_view_window;index:idx_post_view_day;constraint:OnDelete:CASCADE;" json:"post_id"`
+	ViewerKey   string    `gorm:"size:64;not null;uniqueIndex:idx_post_view_window" json:"-"` // u:<id> или a:<отпечаток>
+	WindowStart time.Time `gorm:"not null;uniqueIndex:idx_post_view_window" json:"window_start"`
+	Day         time.Time `gorm:"type:date;not null;index:idx_post_view_day" json:"day"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// PostDailyStat - агрегированные просмотры поста за день
+type PostDailyStat struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID        uint      `gorm:"not null;uniqueIndex:idx_post_daily_stat;constraint:OnDelete:CASCADE;" json:"post_id"`
+	Day           time.Time `gorm:"type:date;not null;uniqueIndex:idx_post_daily_stat" json:"day"`
+	Views         int       `gorm:"not null;default:0" json:"views"`
+	UniqueReaders int       `gorm:"not null;default:0" json:"unique_readers"`
+}
@@ -0,0 +1,140 @@
+// Package feed собирает данные карточек для страницы постов: авторов, теги, обложки и счётчики.
+// Число запросов не зависит от размера страницы - по одному на каждый вид данных.
+package feed
+
+import (
+	"padaroja/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// Подпись автора, если пользователь удалён
+const UnknownAuthor = "Неизвестный пользователь"
+
+// Card - данные карточки поста, загруженные для всей страницы сразу
+type Card struct {
+	Author        models.User       // только id, username, image_url
+	Tags          []string          // никогда не nil
+	Cover         *models.PostPhoto // первое одобренное фото, адрес заменён средней миниатюрой
+	PhotosCount   int
+	CommentsCount int
+}
+
+// AuthorName - имя автора карточки или подпись для удалённого пользователя
+func (c Card) AuthorName() string {
+	if c.Author.ID == 0 {
+		return UnknownAuthor
+	}
+	return c.Author.Username
+}
+
+// Photos - обложка списком, как поле photos в карточках ленты
+func (c Card) Photos() []models.PostPhoto {
+	if c.Cover == nil {
+		return []models.PostPhoto{}
+	}
+	return []models.PostPhoto{*c.Cover}
+}
+
+// Cards загружает карточки для постов страницы: авторы, теги, обложки с числом фото, число комментариев.
+// Для каждого поста из posts в результате есть запись, даже если данных нет.
+func Cards(db *gorm.DB, posts []models.Post) (map[uint]Card, error) {
+	cards := make(map[uint]Card, len(posts))
+	if len(posts) == 0 {
+		return cards, nil
+	}
+
+	postIDs := make([]uint, 0, len(posts))
+	userIDs := make([]int, 0, len(posts))
+	for _, p := range posts {
+		postIDs = append(postIDs, p.ID)
+		userIDs = append(userIDs, p.UserID)
+	}
+
+	authors, err := Authors(db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagRows []struct {
+		PostID uint
+		Name   string
+	}
+	if err := db.Table("post_tags").
+		Select("post_tags.post_id, tags.name").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", postIDs).
+		Order("post_tags.post_id, post_tags.id").
+		Scan(&tagRows).Error; err != nil {
+		return nil, err
+	}
+
+	// Первое фото каждого поста и число фото - одним запросом
+	var covers []struct {
+		models.PostPhoto
+		PhotosCount int
+	}
+	if err := db.Raw(`
+		SELECT DISTINCT ON (post_id) post_photos.*, COUNT(*) OVER (PARTITION BY post_id) AS photos_count
+		FROM post_photos
+		WHERE post_id IN ? AND is_approved = true
+		ORDER BY post_id, "order" ASC, id ASC
+	`, postIDs).Scan(&covers).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []struct {
+		PostID uint
+		Count  int
+	}
+	if err := db.Model(&models.Comment{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ? AND is_approved = ?", postIDs, true).
+		Group("post_id").
+		Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	for _, p := range posts {
+		cards[p.ID] = Card{Author: authors[p.UserID], Tags: []string{}}
+	}
+	for _, t := range tagRows {
+		card := cards[t.PostID]
+		card.Tags = append(card.Tags, t.Name)
+		cards[t.PostID] = card
+	}
+	for i := range covers {
+		photo := covers[i].PostPhoto
+		if photo.ThumbnailMedium != "" {
+			photo.Url = photo.ThumbnailMedium
+		}
+		card := cards[photo.PostID]
+		card.Cover = &photo
+		card.PhotosCount = covers[i].PhotosCount
+		cards[photo.PostID] = card
+	}
+	for _, cm := range comments {
+		card := cards[cm.PostID]
+		card.CommentsCount = cm.Count
+		cards[cm.PostID] = card
+	}
+
+	return cards, nil
+}
+
+// Authors загружает авторов одним запросом: id, имя и аватар
+func Authors(db *gorm.DB, userIDs []int) (map[int]models.User, error) {
+	authors := make(map[int]models.User, len(userIDs))
+	if len(userIDs) == 0 {
+		return authors, nil
+	}
+
+	var users []models.User
+	if err := db.Select("id, username, image_url").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		authors[u.ID] = u
+	}
+	return authors, nil
+}
@@ -0,0 +1,181 @@
+package feed
+
+import (
+	"fmt"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Схема для тестовых данных; создаётся заново и удаляется после бенчмарка
+const benchSchema = "feed_bench"
+
+// Сколько постов засеять: хватает на самую большую страницу
+const benchPosts = 100
+
+// openBenchDB подключается к тестовой БД из TEST_DATABASE_DSN и засевает посты с тегами, фото и комментариями.
+// Без TEST_DATABASE_DSN бенчмарк пропускается.
+func openBenchDB(b *testing.B) (*gorm.DB, []models.Post) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_DSN не задан")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("подключение к БД: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Одно соединение, чтобы search_path действовал на все запросы
+	sqlDB.SetMaxOpenConns(1)
+	b.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS " + benchSchema + " CASCADE")
+		sqlDB.Close()
+	})
+
+	for _, stmt := range []string{
+		"DROP SCHEMA IF EXISTS " + benchSchema + " CASCADE",
+		"CREATE SCHEMA " + benchSchema,
+		"SET search_path TO " + benchSchema,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			b.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := database.Migrate(db); err != nil {
+		b.Fatal(err)
+	}
+
+	posts, err := seed(db)
+	if err != nil {
+		b.Fatalf("заполнение тестовых данных: %v", err)
+	}
+	return db, posts
+}
+
+// seed создаёт авторов, теги и посты; у каждого поста 3 тега, 4 фото и 5 комментариев
+func seed(db *gorm.DB) ([]models.Post, error) {
+	settlement := models.Settlement{Geonameid: 625144, Name: "Minsk", Latitude: 53.9, Longitude: 27.56}
+	if err := db.Create(&settlement).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]models.User, 10)
+	for i := range users {
+		users[i] = models.User{
+			Username:     fmt.Sprintf("bench_user_%d", i),
+			Email:        fmt.Sprintf("bench_user_%d@example.com", i),
+			PasswordHash: "x",
+			RoleID:       1,
+		}
+	}
+	if err := db.Create(&users).Error; err != nil {
+		return nil, err
+	}
+
+	tags := make([]models.Tags, 20)
+	for i := range tags {
+		tags[i] = models.Tags{Name: fmt.Sprintf("bench_tag_%d", i)}
+	}
+	if err := db.Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	posts := make([]models.Post, benchPosts)
+	for i := range posts {
+		posts[i] = models.Post{
+			UserID:         users[i%len(users)].ID,
+			SettlementID:   settlement.Geonameid,
+			SettlementName: settlement.Name,
+			Title:          fmt.Sprintf("Пост %d", i),
+			IsApproved:     true,
+			Status:         models.PostStatusPublished,
+		}
+	}
+	if err := db.Omit("User", "Settlement").Create(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	var postTags []models.PostTag
+	var photos []models.PostPhoto
+	var comments []models.Comment
+	for i, p := range posts {
+		for j := 0; j < 3; j++ {
+			postTags = append(postTags, models.PostTag{PostID: p.ID, TagID: tags[(i+j)%len(tags)].ID})
+		}
+		for j := 0; j < 4; j++ {
+			photos = append(photos, models.PostPhoto{PostID: p.ID, Url: fmt.Sprintf("/uploads/%d_%d.jpg", p.ID, j), Order: j, IsApproved: true})
+		}
+		for j := 0; j < 5; j++ {
+			comments = append(comments, models.Comment{PostID: p.ID, UserID: users[j%len(users)].ID, Content: "Комментарий", IsApproved: true})
+		}
+	}
+	if err := db.Omit("Post", "Tag").Create(&postTags).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Create(&photos).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Omit("User", "Post", "Parent").Create(&comments).Error; err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// countQueries считает запросы, выполненные через db, с помощью колбэков GORM
+func countQueries(b *testing.B, db *gorm.DB) *atomic.Int64 {
+	var count atomic.Int64
+	inc := func(*gorm.DB) { count.Add(1) }
+	if err := db.Callback().Query().After("gorm:query").Register("feed_bench:count_query", inc); err != nil {
+		b.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("feed_bench:count_row", inc); err != nil {
+		b.Fatal(err)
+	}
+	return &count
+}
+
+// BenchmarkCards проверяет, что число запросов Cards не растёт с размером страницы
+func BenchmarkCards(b *testing.B) {
+	db, posts := openBenchDB(b)
+	count := countQueries(b, db)
+
+	sizes := []int{10, 50, 100}
+	perCall := make(map[int]int64, len(sizes))
+	for _, size := range sizes {
+		page := posts[:size]
+		b.Run(fmt.Sprintf("page_%d", size), func(b *testing.B) {
+			count.Store(0)
+			for i := 0; i < b.N; i++ {
+				cards, err := Cards(db, page)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(cards) != size {
+					b.Fatalf("получено %d карточек вместо %d", len(cards), size)
+				}
+				card := cards[page[0].ID]
+				if len(card.Tags) != 3 || card.PhotosCount != 4 || card.CommentsCount != 5 || card.Cover == nil {
+					b.Fatalf("неполная карточка: %+v", card)
+				}
+			}
+			perCall[size] = count.Load() / int64(b.N)
+			b.ReportMetric(float64(perCall[size]), "queries/op")
+		})
+	}
+
+	for _, size := range sizes[1:] {
+		if perCall[size] != perCall[sizes[0]] {
+			b.Fatalf("число запросов зависит от размера страницы: %v", perCall)
+		}
+	}
+}
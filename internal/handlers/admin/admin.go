@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"padaroja/internal/domain/models"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strings"
 	"time"
 
@@ -101,17 +102,35 @@ func GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	var users []models.User
-	err := database.DB.Select("id, username, email, role_id, is_blocked, created_at, image_url").
-		Order("created_at DESC").
-		Find(&users).Error
-
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Select("id, username, email, role_id, is_blocked, created_at, image_url").
+		Order("created_at DESC, id DESC")
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("created_at", "id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
+	var users []models.User
+	if err := query.Limit(page.Limit + 1).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	users, hasMore := utils.TrimPage(users, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := users[len(users)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Time: last.Created_at, ID: uint(last.ID)}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":       users,
+		"next_cursor": nextCursor,
+	})
 }
 
 // GetModerators - получение списка модераторов с историей назначений
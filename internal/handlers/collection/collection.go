@@ -0,0 +1,394 @@
+package collection
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CollectionRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	CoverUrl    string `json:"cover_url"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type CollectionUpdateRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	CoverUrl    *string `json:"cover_url"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+var (
+	errNotCurator              = errors.New("only the owner and curators can edit this collection")
+	errPostAlreadyInCollection = errors.New("post is already in the collection")
+	errReorderMismatch         = errors.New("post_ids must list every post of the collection exactly once")
+)
+
+// getUserID возвращает ID текущего пользователя, если он авторизован
+func getUserID(c *gin.Context) (int, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := userIDValue.(uint)
+	if !ok {
+		return 0, false
+	}
+	return int(userID), true
+}
+
+func isCurator(db *gorm.DB, collectionID uint, userID int) bool {
+	var count int64
+	db.Model(&models.CollectionCurator{}).
+		Where("collection_id = ? AND user_id = ?", collectionID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// canEditCollection - владелец или соавтор подборки
+func canEditCollection(db *gorm.DB, collection models.Collection, userID int) bool {
+	return collection.UserID == userID || isCurator(db, collection.ID, userID)
+}
+
+// canViewCollection - публичные подборки видят все, приватные - только владелец и соавторы
+func canViewCollection(db *gorm.DB, collection models.Collection, userID int, authorized bool) bool {
+	if collection.IsPublic {
+		return true
+	}
+	return authorized && canEditCollection(db, collection, userID)
+}
+
+// LoadViewableCollection загружает подборку из параметра пути и проверяет доступ на чтение (используется и картой)
+func LoadViewableCollection(c *gin.Context) (models.Collection, bool) {
+	var collection models.Collection
+
+	collectionID, err := strconv.ParseUint(c.Param("collectionID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return collection, false
+	}
+
+	if err := database.DB.First(&collection, collectionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return collection, false
+	}
+
+	userID, authorized := getUserID(c)
+	if !canViewCollection(database.DB, collection, userID, authorized) {
+		// Приватная подборка для посторонних не существует
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return collection, false
+	}
+
+	return collection, true
+}
+
+// loadEditableCollection загружает подборку и проверяет, что текущий пользователь - владелец или соавтор
+func loadEditableCollection(c *gin.Context) (models.Collection, int, bool) {
+	var collection models.Collection
+
+	userID, exists := getUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return collection, 0, false
+	}
+
+	collectionID, err := strconv.ParseUint(c.Param("collectionID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return collection, 0, false
+	}
+
+	if err := database.DB.First(&collection, collectionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return collection, 0, false
+	}
+
+	if !canEditCollection(database.DB, collection, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errNotCurator.Error()})
+		return collection, 0, false
+	}
+
+	return collection, userID, true
+}
+
+// collectionCover - обложка подборки; если не задана, берём первое фото первого поста
+func collectionCover(db *gorm.DB, collection models.Collection) string {
+	if collection.CoverUrl != "" {
+		return collection.CoverUrl
+	}
+
+	var photo models.PostPhoto
+	err := db.Table("post_photos").
+		Select("post_photos.*").
+		Joins("JOIN collection_items ON collection_items.post_id = post_photos.post_id").
+		Joins("JOIN posts ON posts.id = post_photos.post_id").
+		Where("collection_items.collection_id = ? AND posts.status = ? AND posts.is_approved = ? AND posts.deleted_at IS NULL",
+			collection.ID, models.PostStatusPublished, true).
+		Order("collection_items.position ASC, post_photos.\"order\" ASC").
+		First(&photo).Error
+	if err != nil {
+		return ""
+	}
+	if photo.ThumbnailMedium != "" {
+		return photo.ThumbnailMedium
+	}
+	return photo.Url
+}
+
+// collectionSummary - краткое описание подборки для списков
+func collectionSummary(db *gorm.DB, collection models.Collection) gin.H {
+	// Считаем только посты, которые видны в ленте подборки
+	var postsCount int64
+	db.Model(&models.CollectionItem{}).
+		Joins("JOIN posts ON posts.id = collection_items.post_id").
+		Where("collection_items.collection_id = ?", collection.ID).
+		Scopes(visiblePosts).
+		Count(&postsCount)
+
+	return gin.H{
+		"id":          collection.ID,
+		"user_id":     collection.UserID,
+		"title":       collection.Title,
+		"description": collection.Description,
+		"cover_url":   collectionCover(db, collection),
+		"is_public":   collection.IsPublic,
+		"posts_count": postsCount,
+		"created_at":  collection.CreatedAt,
+		"updated_at":  collection.UpdatedAt,
+	}
+}
+
+// PublicCollectionsSummary возвращает публичные подборки пользователя (используется в профиле)
+func PublicCollectionsSummary(db *gorm.DB, userID int) []gin.H {
+	var collections []models.Collection
+	db.Where("user_id = ? AND is_public = ?", userID, true).
+		Order("updated_at DESC").
+		Find(&collections)
+
+	result := make([]gin.H, 0, len(collections))
+	for _, collection := range collections {
+		result = append(result, collectionSummary(db, collection))
+	}
+	return result
+}
+
+// CreateCollection - создание подборки
+func CreateCollection(c *gin.Context) {
+	userID, exists := getUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input CollectionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" || len([]rune(input.Title)) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required and must be at most 200 characters"})
+		return
+	}
+
+	collection := models.Collection{
+		UserID:      userID,
+		Title:       input.Title,
+		Description: strings.TrimSpace(input.Description),
+		CoverUrl:    strings.TrimSpace(input.CoverUrl),
+		IsPublic:    input.IsPublic,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := database.DB.Create(&collection).Error; err != nil {
+		log.Printf("Ошибка создания подборки: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, collectionSummary(database.DB, collection))
+}
+
+// GetMyCollections - подборки текущего пользователя и подборки, где он соавтор
+func GetMyCollections(c *gin.Context) {
+	userID, exists := getUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var collections []models.Collection
+	if err := database.DB.
+		Where("user_id = ? OR id IN (?)", userID,
+			database.DB.Model(&models.CollectionCurator{}).Select("collection_id").Where("user_id = ?", userID)).
+		Order("updated_at DESC").
+		Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(collections))
+	for _, collection := range collections {
+		item := collectionSummary(database.DB, collection)
+		item["is_owner"] = collection.UserID == userID
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserCollections - подборки пользователя: чужие видны только публичные
+func GetUserCollections(c *gin.Context) {
+	profileUserID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	query := database.DB.Where("user_id = ?", profileUserID)
+	if userID, ok := getUserID(c); !ok || userID != profileUserID {
+		query = query.Where("is_public = ?", true)
+	}
+
+	var collections []models.Collection
+	if err := query.Order("updated_at DESC").Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(collections))
+	for _, collection := range collections {
+		response = append(response, collectionSummary(database.DB, collection))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCollection - подборка с владельцем и соавторами
+func GetCollection(c *gin.Context) {
+	collection, ok := LoadViewableCollection(c)
+	if !ok {
+		return
+	}
+
+	var owner models.User
+	database.DB.Select("id, username, image_url").First(&owner, collection.UserID)
+
+	var curators []models.CollectionCurator
+	database.DB.Where("collection_id = ?", collection.ID).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, username, image_url")
+		}).
+		Order("added_at ASC").
+		Find(&curators)
+
+	curatorList := make([]gin.H, 0, len(curators))
+	for _, curator := range curators {
+		curatorList = append(curatorList, gin.H{
+			"user_id":  curator.UserID,
+			"username": curator.User.Username,
+			"avatar":   curator.User.ImageUrl,
+			"added_at": curator.AddedAt,
+		})
+	}
+
+	userID, authorized := getUserID(c)
+
+	response := collectionSummary(database.DB, collection)
+	response["owner"] = gin.H{
+		"id":       owner.ID,
+		"username": owner.Username,
+		"avatar":   owner.ImageUrl,
+	}
+	response["curators"] = curatorList
+	response["can_edit"] = authorized && canEditCollection(database.DB, collection, userID)
+	response["is_owner"] = authorized && collection.UserID == userID
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCollection - изменение названия, описания и обложки; видимость меняет только владелец
+func UpdateCollection(c *gin.Context) {
+	collection, userID, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	var input CollectionUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" || len([]rune(title)) > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required and must be at most 200 characters"})
+			return
+		}
+		collection.Title = title
+	}
+	if input.Description != nil {
+		collection.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.CoverUrl != nil {
+		collection.CoverUrl = strings.TrimSpace(*input.CoverUrl)
+	}
+	if input.IsPublic != nil {
+		if collection.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can change collection visibility"})
+			return
+		}
+		collection.IsPublic = *input.IsPublic
+	}
+	collection.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(&collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collectionSummary(database.DB, collection))
+}
+
+// DeleteCollection - удаление подборки (только владелец); сами посты не затрагиваются
+func DeleteCollection(c *gin.Context) {
+	collection, userID, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	if collection.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can delete the collection"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionCurator{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
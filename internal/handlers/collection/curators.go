@@ -0,0 +1,98 @@
+package collection
+
+import (
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddCuratorRequest struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+// AddCurator - владелец добавляет соавтора подборки
+func AddCurator(c *gin.Context) {
+	collection, userID, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+	if collection.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can manage curators"})
+		return
+	}
+
+	var input AddCuratorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.UserID == collection.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Owner is already a curator"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, username, image_url").First(&user, input.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if isCurator(database.DB, collection.ID, input.UserID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a curator"})
+		return
+	}
+
+	curator := models.CollectionCurator{
+		CollectionID: collection.ID,
+		UserID:       input.UserID,
+		AddedAt:      time.Now(),
+	}
+	if err := database.DB.Create(&curator).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add curator"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"avatar":   user.ImageUrl,
+		"added_at": curator.AddedAt,
+	})
+}
+
+// RemoveCurator - владелец убирает соавтора, соавтор может выйти сам
+func RemoveCurator(c *gin.Context) {
+	collection, userID, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	curatorID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if collection.UserID != userID && curatorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can manage curators"})
+		return
+	}
+
+	result := database.DB.Where("collection_id = ? AND user_id = ?", collection.ID, curatorID).
+		Delete(&models.CollectionCurator{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove curator"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Curator not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Curator removed"})
+}
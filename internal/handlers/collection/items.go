@@ -0,0 +1,251 @@
+package collection
+
+import (
+	"errors"
+	"net/http"
+	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AddPostRequest struct {
+	PostID uint `json:"post_id" binding:"required"`
+}
+
+type ReorderRequest struct {
+	PostIDs []uint `json:"post_ids" binding:"required"`
+}
+
+// visiblePosts - в ленте и на карте подборки показываются только опубликованные и одобренные посты не из корзины
+func visiblePosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ? AND posts.is_approved = ? AND posts.deleted_at IS NULL", models.PostStatusPublished, true)
+}
+
+// AddPostToCollection - добавление поста в конец подборки
+func AddPostToCollection(c *gin.Context) {
+	collection, userID, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	var input AddPostRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var post models.Post
+	if err := visiblePosts(database.DB.Model(&models.Post{})).First(&post, "posts.id = ?", input.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	var item models.CollectionItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ? AND post_id = ?", collection.ID, post.ID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errPostAlreadyInCollection
+		}
+
+		var maxPosition int
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ?", collection.ID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		item = models.CollectionItem{
+			CollectionID: collection.ID,
+			PostID:       post.ID,
+			Position:     maxPosition + 1,
+			AddedBy:      userID,
+			AddedAt:      time.Now(),
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&collection).Update("updated_at", time.Now()).Error
+	})
+
+	if errors.Is(err, errPostAlreadyInCollection) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add post to collection"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// RemovePostFromCollection - удаление поста из подборки, позиции остальных сдвигаются
+func RemovePostFromCollection(c *gin.Context) {
+	collection, _, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	postID, err := strconv.ParseUint(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var item models.CollectionItem
+		if err := tx.Where("collection_id = ? AND post_id = ?", collection.ID, postID).First(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ? AND position > ?", collection.ID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&collection).Update("updated_at", time.Now()).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post is not in the collection"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove post from collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post removed from collection"})
+}
+
+// ReorderCollection - новый порядок постов; передаются все посты подборки
+func ReorderCollection(c *gin.Context) {
+	collection, _, ok := loadEditableCollection(c)
+	if !ok {
+		return
+	}
+
+	var input ReorderRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ?", collection.ID).
+			Pluck("post_id", &current).Error; err != nil {
+			return err
+		}
+
+		if len(current) != len(input.PostIDs) {
+			return errReorderMismatch
+		}
+		known := make(map[uint]bool, len(current))
+		for _, id := range current {
+			known[id] = true
+		}
+		for _, id := range input.PostIDs {
+			if !known[id] {
+				return errReorderMismatch
+			}
+			delete(known, id) // повторы тоже считаются ошибкой
+		}
+
+		for i, postID := range input.PostIDs {
+			if err := tx.Model(&models.CollectionItem{}).
+				Where("collection_id = ? AND post_id = ?", collection.ID, postID).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&collection).Update("updated_at", time.Now()).Error
+	})
+
+	if errors.Is(err, errReorderMismatch) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection reordered", "post_ids": input.PostIDs})
+}
+
+// GetCollectionPosts - лента постов подборки в заданном порядке
+func GetCollectionPosts(c *gin.Context) {
+	collection, ok := LoadViewableCollection(c)
+	if !ok {
+		return
+	}
+
+	var items []models.CollectionItem
+	if err := database.DB.
+		Joins("JOIN posts ON posts.id = collection_items.post_id").
+		Where("collection_items.collection_id = ?", collection.ID).
+		Scopes(visiblePosts).
+		Order("collection_items.position ASC").
+		Preload("Post").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection posts"})
+		return
+	}
+
+	// Авторы, теги, обложки и счётчики - пакетом для всей подборки
+	posts := make([]models.Post, 0, len(items))
+	for _, item := range items {
+		posts = append(posts, item.Post)
+	}
+	cards, err := feed.Cards(database.DB, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection posts"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		p := item.Post
+		card := cards[p.ID]
+
+		response = append(response, gin.H{
+			"id":              p.ID,
+			"user_id":         p.UserID,
+			"title":           p.Title,
+			"created_at":      p.CreatedAt,
+			"settlement_name": p.SettlementName,
+			"settlement_id":   p.SettlementID,
+			"tags":            card.Tags,
+			"photos":          card.Photos(),
+			"photos_count":    card.PhotosCount,
+			"comments_count":  card.CommentsCount,
+			"likes_count":     p.LikesCount,
+			"user_avatar":     card.Author.ImageUrl,
+			"user_name":       card.AuthorName(),
+			"position":        item.Position,
+			"added_by":        item.AddedBy,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collectionSummary(database.DB, collection),
+		"posts":      response,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"padaroja/internal/domain/models"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -83,9 +84,12 @@ func GetComments(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
-	offset := (page - 1) * limit
+	// Комментарии обычно загружаются целиком, поэтому страница по умолчанию больше обычной
+	page, err := utils.ParsePage(c.Query, utils.MaxPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	var allComments []models.Comment
 	var total int64
@@ -95,6 +99,10 @@ func GetComments(c *gin.Context) {
 
 	query.Model(&models.Comment{}).Count(&total)
 
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("created_at", "id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
 	err = query.
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id, username, image_url")
@@ -105,9 +113,8 @@ func GetComments(c *gin.Context) {
 		Preload("Parent.User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id, username")
 		}).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit + 1).
 		Find(&allComments).Error
 
 	if err != nil {
@@ -115,12 +122,19 @@ func GetComments(c *gin.Context) {
 		return
 	}
 
+	allComments, hasMore := utils.TrimPage(allComments, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := allComments[len(allComments)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Time: last.CreatedAt, ID: last.ID}))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"comments": allComments,
-		"total":    total,
-		"page":     page,
-		"limit":    limit,
-		"has_more": int64(offset+len(allComments)) < total,
+		"comments":    allComments,
+		"total":       total,
+		"limit":       page.Limit,
+		"has_more":    hasMore,
+		"next_cursor": nextCursor,
 	})
 }
 
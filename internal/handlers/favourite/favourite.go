@@ -3,7 +3,9 @@ package favourite
 import (
 	"net/http"
 	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
 	"strings"
 
@@ -135,60 +137,82 @@ func GetFavourites(c *gin.Context) {
 		return
 	}
 
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Сначала недавно добавленные; посты из корзины не показываем
+	query := database.DB.
+		Joins("JOIN posts ON posts.id = favourites.post_id AND posts.deleted_at IS NULL").
+		Where("favourites.user_id = ?", userID).
+		Order("favourites.created_at DESC, favourites.id DESC")
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("favourites.created_at", "favourites.id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
 	var favourites []models.Favourite
-	if err := database.DB.
-		Where("user_id = ?", userID).
+	if err := query.
 		Preload("Post").
-		Preload("Post.User").
-		Preload("Post.Photos").
 		Preload("Post.Settlement").
-		Preload("Post.Paragraphs").
+		Limit(page.Limit + 1).
 		Find(&favourites).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favourites"})
 		return
 	}
 
-	response := make([]gin.H, 0)
-	for _, fav := range favourites {
-		if fav.Post.ID == 0 {
-			continue
-		}
-
-		// ИСПРАВЛЕНИЕ: Используем post_tags вместо place_tags
-		var tags []string
-		database.DB.Table("tags").
-			Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
-			Where("post_tags.post_id = ?", fav.Post.ID).
-			Pluck("tags.name", &tags)
+	favourites, hasMore := utils.TrimPage(favourites, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := favourites[len(favourites)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Time: last.CreatedAt, ID: last.ID}))
+	}
 
-		if tags == nil {
-			tags = []string{}
+	// Авторы, теги, обложки и счётчики - пакетом для всей страницы
+	posts := make([]models.Post, 0, len(favourites))
+	for _, fav := range favourites {
+		if fav.Post.ID != 0 {
+			posts = append(posts, fav.Post)
 		}
+	}
+	cards, err := feed.Cards(database.DB, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favourites"})
+		return
+	}
 
-		userAvatar := ""
-		userName := "Неизвестный пользователь"
-		if fav.Post.User.ID != 0 {
-			userAvatar = fav.Post.User.ImageUrl
-			userName = fav.Post.User.Username
+	response := make([]gin.H, 0, len(favourites))
+	for _, fav := range favourites {
+		if fav.Post.ID == 0 {
+			continue
 		}
+		card := cards[fav.Post.ID]
 
 		response = append(response, gin.H{
-			"id":              fav.Post.ID,
-			"user_id":         fav.Post.UserID,
-			"title":           fav.Post.Title,
-			"created_at":      fav.Post.CreatedAt,
-			"settlement_name": fav.Post.SettlementName,
-			"settlement_id":   fav.Post.SettlementID,
-			"tags":            tags,
-			"photos":          fav.Post.Photos,
-			"likes_count":     fav.Post.LikesCount,
-			"user_avatar":     userAvatar,
-			"user_name":       userName,
-			"is_favourite":    true,
+			"id":                   fav.Post.ID,
+			"user_id":              fav.Post.UserID,
+			"title":                fav.Post.Title,
+			"created_at":           fav.Post.CreatedAt,
+			"settlement_name":      fav.Post.SettlementName,
+			"settlement_id":        fav.Post.SettlementID,
+			"tags":                 card.Tags,
+			"photos":               card.Photos(),
+			"photos_count":         card.PhotosCount,
+			"comments_count":       card.CommentsCount,
+			"likes_count":          fav.Post.LikesCount,
+			"user_avatar":          card.Author.ImageUrl,
+			"user_name":            card.AuthorName(),
+			"is_favourite":         true,
+			"preview_text":         fav.Post.PreviewText,
+			"reading_time_minutes": fav.Post.ReadingTimeMinutes,
 		})
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       response,
+		"next_cursor": nextCursor,
+	})
 }
 
 func CheckFavourite(c *gin.Context) {
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"padaroja/internal/domain/models"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -157,24 +158,45 @@ func GetFollowersList(c *gin.Context) {
 		return
 	}
 
-	var followers []struct {
-		models.User
-		FollowID int `json:"follow_id"`
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	result := database.DB.
+	// Сначала недавние подписки
+	query := database.DB.
 		Table("followers").
 		Select("users.*, followers.id as follow_id").
 		Joins("LEFT JOIN users ON users.id = followers.follower_id").
 		Where("followers.followed_id = ?", userID).
-		Find(&followers)
+		Order("followers.id DESC")
+	if page.Cursor != nil {
+		query = query.Where("followers.id < ?", page.Cursor.ID)
+	}
+
+	var followers []struct {
+		models.User
+		FollowID int `json:"follow_id"`
+	}
+
+	result := query.Limit(page.Limit + 1).Find(&followers)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get followers"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"followers": followers})
+	followers, hasMore := utils.TrimPage(followers, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{ID: uint(followers[len(followers)-1].FollowID)}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"followers":   followers,
+		"next_cursor": nextCursor,
+	})
 }
 
 func GetFollowingList(c *gin.Context) {
@@ -185,22 +207,43 @@ func GetFollowingList(c *gin.Context) {
 		return
 	}
 
-	var following []struct {
-		models.User
-		FollowID int `json:"follow_id"`
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	result := database.DB.
+	// Сначала недавние подписки
+	query := database.DB.
 		Table("followers").
 		Select("users.*, followers.id as follow_id").
 		Joins("LEFT JOIN users ON users.id = followers.followed_id").
 		Where("followers.follower_id = ?", userID).
-		Find(&following)
+		Order("followers.id DESC")
+	if page.Cursor != nil {
+		query = query.Where("followers.id < ?", page.Cursor.ID)
+	}
+
+	var following []struct {
+		models.User
+		FollowID int `json:"follow_id"`
+	}
+
+	result := query.Limit(page.Limit + 1).Find(&following)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get following"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"following": following})
+	following, hasMore := utils.TrimPage(following, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{ID: uint(following[len(following)-1].FollowID)}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following":   following,
+		"next_cursor": nextCursor,
+	})
 }
@@ -4,7 +4,9 @@ import (
 	"log"
 	"net/http"
 	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -202,58 +204,81 @@ func GetUserLikes(c *gin.Context) {
 		}
 	}
 
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Сначала недавно добавленные; посты из корзины не показываем
+	query := database.DB.
+		Joins("JOIN posts ON posts.id = likes.post_id AND posts.deleted_at IS NULL").
+		Where("likes.user_id = ?", userID).
+		Order("likes.created_at DESC, likes.id DESC")
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("likes.created_at", "likes.id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
 	var likes []models.Like
-	if err := database.DB.
-		Where("user_id = ?", userID).
+	if err := query.
 		Preload("Post").
-		Preload("Post.User").
-		Preload("Post.Photos").
 		Preload("Post.Settlement").
-		Preload("Post.Paragraphs").
+		Limit(page.Limit + 1).
 		Find(&likes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch liked posts"})
 		return
 	}
 
-	response := make([]gin.H, 0)
-	for _, like := range likes {
-		if like.Post.ID == 0 {
-			continue
-		}
-
-		var tags []string
-		database.DB.Table("tags").
-			Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
-			Where("post_tags.post_id = ?", like.Post.ID).
-			Pluck("tags.name", &tags)
+	likes, hasMore := utils.TrimPage(likes, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := likes[len(likes)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Time: last.CreatedAt, ID: last.ID}))
+	}
 
-		if tags == nil {
-			tags = []string{}
+	// Авторы, теги, обложки и счётчики - пакетом для всей страницы
+	posts := make([]models.Post, 0, len(likes))
+	for _, like := range likes {
+		if like.Post.ID != 0 {
+			posts = append(posts, like.Post)
 		}
+	}
+	cards, err := feed.Cards(database.DB, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch liked posts"})
+		return
+	}
 
-		userAvatar := ""
-		userName := "Неизвестный пользователь"
-		if like.Post.User.ID != 0 {
-			userAvatar = like.Post.User.ImageUrl
-			userName = like.Post.User.Username
+	response := make([]gin.H, 0, len(likes))
+	for _, like := range likes {
+		if like.Post.ID == 0 {
+			continue
 		}
+		card := cards[like.Post.ID]
 
 		response = append(response, gin.H{
-			"id":          like.Post.ID,
-			"user_id":     like.Post.UserID,
-			"title":       like.Post.Title,
-			"created_at":  like.Post.CreatedAt,
-			"place_name":  like.Post.SettlementName,
-			"tags":        tags,
-			"photos":      like.Post.Photos,
-			"likes_count": like.Post.LikesCount,
-			"user_avatar": userAvatar,
-			"user_name":   userName,
-			"is_liked":    true,
+			"id":                   like.Post.ID,
+			"user_id":              like.Post.UserID,
+			"title":                like.Post.Title,
+			"created_at":           like.Post.CreatedAt,
+			"place_name":           like.Post.SettlementName,
+			"tags":                 card.Tags,
+			"photos":               card.Photos(),
+			"photos_count":         card.PhotosCount,
+			"comments_count":       card.CommentsCount,
+			"likes_count":          like.Post.LikesCount,
+			"user_avatar":          card.Author.ImageUrl,
+			"user_name":            card.AuthorName(),
+			"is_liked":             true,
+			"preview_text":         like.Post.PreviewText,
+			"reading_time_minutes": like.Post.ReadingTimeMinutes,
 		})
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       response,
+		"next_cursor": nextCursor,
+	})
 }
 
 func CheckLike(c *gin.Context) {
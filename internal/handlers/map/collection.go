@@ -0,0 +1,81 @@
+package maps
+
+import (
+	"net/http"
+	"padaroja/internal/domain/models"
+	collectionhandlers "padaroja/internal/handlers/collection"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCollectionMapData - посты подборки на карте в порядке подборки
+func GetCollectionMapData(c *gin.Context) {
+	// Приватную подборку видят только владелец и соавторы
+	collection, ok := collectionhandlers.LoadViewableCollection(c)
+	if !ok {
+		return
+	}
+
+	var items []models.CollectionItem
+	if err := database.DB.
+		Joins("JOIN posts ON posts.id = collection_items.post_id").
+		Where("collection_items.collection_id = ?", collection.ID).
+		Where("posts.status = ? AND posts.is_approved = ? AND posts.deleted_at IS NULL", models.PostStatusPublished, true).
+		Order("collection_items.position ASC").
+		Preload("Post.Photos").
+		Preload("Post.Settlement").
+		Preload("Post.Stops", orderStops).
+		Preload("Post.Stops.Settlement").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	markers := make([]gin.H, 0, len(items))
+	// Маршрут подборки соединяет посты в порядке подборки
+	collectionRoute := make([][2]float64, 0, len(items))
+
+	for _, item := range items {
+		post := item.Post
+		if post.Settlement.Latitude == 0 && post.Settlement.Longitude == 0 {
+			continue
+		}
+
+		polyline, stops := tripRoute(post)
+		collectionRoute = append(collectionRoute, polyline...)
+
+		var photoURLs []string
+		for _, photo := range post.Photos {
+			photoURLs = append(photoURLs, photo.Url)
+		}
+
+		markers = append(markers, gin.H{
+			"id":          post.ID,
+			"title":       post.Title,
+			"position":    item.Position,
+			"place_id":    post.SettlementID,
+			"place_name":  post.SettlementName,
+			"latitude":    post.Settlement.Latitude,
+			"longitude":   post.Settlement.Longitude,
+			"created_at":  post.CreatedAt,
+			"photos":      photoURLs,
+			"likes_count": post.LikesCount,
+			"user_id":     post.UserID,
+			"route":       stops,
+			"polyline":    polyline,
+			"distance_km": post.RouteDistanceKm,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": markers,
+		"collection": gin.H{
+			"id":          collection.ID,
+			"title":       collection.Title,
+			"polyline":    collectionRoute,
+			"distance_km": utils.RouteDistanceKm(collectionRoute),
+		},
+	})
+}
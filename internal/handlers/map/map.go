@@ -3,12 +3,46 @@ package maps
 import (
 	"net/http"
 	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+func orderStops(db *gorm.DB) *gorm.DB {
+	return db.Order("trip_stops.order ASC")
+}
+
+// tripRoute возвращает ломаную маршрута поездки [[широта, долгота], ...] и список остановок.
+// Для поста без остановок маршрут состоит из одной точки - основного населённого пункта.
+func tripRoute(post models.Post) ([][2]float64, []gin.H) {
+	polyline := make([][2]float64, 0, len(post.Stops))
+	stops := make([]gin.H, 0, len(post.Stops))
+
+	for _, stop := range post.Stops {
+		if stop.Settlement.Latitude == 0 && stop.Settlement.Longitude == 0 {
+			continue
+		}
+		polyline = append(polyline, [2]float64{stop.Settlement.Latitude, stop.Settlement.Longitude})
+		stops = append(stops, gin.H{
+			"order":      stop.Order,
+			"place_id":   stop.SettlementID,
+			"place_name": stop.SettlementName,
+			"latitude":   stop.Settlement.Latitude,
+			"longitude":  stop.Settlement.Longitude,
+		})
+	}
+
+	if len(polyline) == 0 && (post.Settlement.Latitude != 0 || post.Settlement.Longitude != 0) {
+		polyline = append(polyline, [2]float64{post.Settlement.Latitude, post.Settlement.Longitude})
+	}
+
+	return polyline, stops
+}
+
 // Найдите функцию GetUserMapData или GetMapDataByUserID
 // И добавьте Preload("Photos") в запрос:
 
@@ -27,12 +61,22 @@ func GetMapDataByUserID(c *gin.Context) {
 		return
 	}
 
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Получаем посты пользователя ТОЛЬКО одобренные
 	var posts []models.Post
 	if err := database.DB.
 		Where("user_id = ? AND is_approved = ?", userID, true). // Добавлен фильтр is_approved
+		Where("status = ?", models.PostStatusPublished).
+		Scopes(visitFilter.Scope).
 		Preload("Photos").
 		Preload("Settlement").
+		Preload("Stops", orderStops).
+		Preload("Stops.Settlement").
 		Find(&posts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
 		return
@@ -41,6 +85,8 @@ func GetMapDataByUserID(c *gin.Context) {
 	// Формируем ответ для карты
 	var markers []gin.H
 	for _, post := range posts {
+		polyline, stops := tripRoute(post)
+
 		// Извлекаем URL фото
 		var photoURLs []string
 		for _, photo := range post.Photos {
@@ -48,16 +94,21 @@ func GetMapDataByUserID(c *gin.Context) {
 		}
 
 		markers = append(markers, gin.H{
-			"id":          post.ID,
-			"title":       post.Title,
-			"place_id":    post.SettlementID,
-			"place_name":  post.SettlementName,
-			"latitude":    post.Settlement.Latitude,
-			"longitude":   post.Settlement.Longitude,
-			"created_at":  post.CreatedAt,
-			"photos":      photoURLs,
-			"likes_count": post.LikesCount,
-			"user_id":     post.UserID,
+			"id":           post.ID,
+			"title":        post.Title,
+			"place_id":     post.SettlementID,
+			"place_name":   post.SettlementName,
+			"latitude":     post.Settlement.Latitude,
+			"longitude":    post.Settlement.Longitude,
+			"created_at":   post.CreatedAt,
+			"photos":       photoURLs,
+			"likes_count":  post.LikesCount,
+			"user_id":      post.UserID,
+			"route":        stops,
+			"polyline":     polyline,
+			"distance_km":  post.RouteDistanceKm,
+			"visited_from": post.VisitedFrom,
+			"visited_to":   post.VisitedTo,
 		})
 	}
 
@@ -88,12 +139,22 @@ func GetUserMapData(c *gin.Context) {
 		return
 	}
 
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Получаем посты пользователя ТОЛЬКО одобренные
 	var posts []models.Post
 	if err := database.DB.
 		Where("user_id = ? AND is_approved = ?", userID, true). // Добавлен фильтр is_approved
+		Where("status = ?", models.PostStatusPublished).
+		Scopes(visitFilter.Scope).
 		Preload("Photos").
 		Preload("Settlement").
+		Preload("Stops", orderStops).
+		Preload("Stops.Settlement").
 		Find(&posts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
 		return
@@ -102,6 +163,8 @@ func GetUserMapData(c *gin.Context) {
 	// Формируем ответ для карты
 	var markers []gin.H
 	for _, post := range posts {
+		polyline, stops := tripRoute(post)
+
 		// Извлекаем URL фото
 		var photoURLs []string
 		for _, photo := range post.Photos {
@@ -109,16 +172,21 @@ func GetUserMapData(c *gin.Context) {
 		}
 
 		markers = append(markers, gin.H{
-			"id":          post.ID,
-			"title":       post.Title,
-			"place_id":    post.SettlementID,
-			"place_name":  post.SettlementName,
-			"latitude":    post.Settlement.Latitude,
-			"longitude":   post.Settlement.Longitude,
-			"created_at":  post.CreatedAt,
-			"photos":      photoURLs,
-			"likes_count": post.LikesCount,
-			"user_id":     post.UserID,
+			"id":           post.ID,
+			"title":        post.Title,
+			"place_id":     post.SettlementID,
+			"place_name":   post.SettlementName,
+			"latitude":     post.Settlement.Latitude,
+			"longitude":    post.Settlement.Longitude,
+			"created_at":   post.CreatedAt,
+			"photos":       photoURLs,
+			"likes_count":  post.LikesCount,
+			"user_id":      post.UserID,
+			"route":        stops,
+			"polyline":     polyline,
+			"distance_km":  post.RouteDistanceKm,
+			"visited_from": post.VisitedFrom,
+			"visited_to":   post.VisitedTo,
 		})
 	}
 
@@ -132,16 +200,68 @@ func GetUserMapData(c *gin.Context) {
 	})
 }
 
+// Маркеров на странице общей карты больше, чем постов в ленте: карта догружает их все
+const (
+	defaultMarkersPageSize = 200
+	maxMarkersPageSize     = 500
+
+	// Метка порядка в курсоре меток карты
+	markersCursorSort = "map"
+)
+
 func GetAllPostsMapData(c *gin.Context) {
-	var posts []models.Post
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Загружаем все одобренные посты с их поселениями и фото
-	if err := database.DB.
+	page, err := utils.ParsePage(c.Query, defaultMarkersPageSize, maxMarkersPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Курсор ленты или комментариев не подходит для меток карты
+	if page.Cursor != nil && page.Cursor.Sort != markersCursorSort {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
+	query := database.DB.
 		Preload("Settlement").
 		Preload("Photos").
+		Preload("Stops", orderStops).
+		Preload("Stops.Settlement").
 		Where("is_approved = ?", true). // Только одобренные посты
-		Order("created_at DESC").
-		Find(&posts).Error; err != nil {
+		Where("status = ?", models.PostStatusPublished).
+		Where("settlement_id > 0"). // Без места пост на карту не попадает
+		Scopes(visitFilter.Scope).
+		Order("created_at DESC, id DESC")
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("created_at", "id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
+	// Загружаем одобренные посты с их поселениями и фото, страницами
+	var posts []models.Post
+	if err := query.Limit(page.Limit + 1).Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch posts"})
+		return
+	}
+
+	posts, hasMore := utils.TrimPage(posts, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := posts[len(posts)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Sort: markersCursorSort, Time: last.CreatedAt, ID: last.ID}))
+	}
+
+	// Авторы меток - одним запросом
+	userIDs := make([]int, 0, len(posts))
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
+	}
+	authors, err := feed.Authors(database.DB, userIDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch posts"})
 		return
 	}
@@ -153,6 +273,8 @@ func GetAllPostsMapData(c *gin.Context) {
 			continue
 		}
 
+		polyline, stops := tripRoute(post)
+
 		// Формируем массив URL фото
 		var photoUrls []string
 		for _, photo := range post.Photos {
@@ -161,30 +283,29 @@ func GetAllPostsMapData(c *gin.Context) {
 			}
 		}
 
-		// Получаем имя пользователя
-		var user models.User
-		userName := ""
-		if err := database.DB.Select("username").First(&user, post.UserID).Error; err == nil {
-			userName = user.Username
-		}
-
 		postMarkers = append(postMarkers, gin.H{
-			"id":          post.ID,
-			"title":       post.Title,
-			"place_id":    post.SettlementID,
-			"place_name":  post.SettlementName,
-			"latitude":    post.Settlement.Latitude,
-			"longitude":   post.Settlement.Longitude,
-			"created_at":  post.CreatedAt,
-			"photos":      photoUrls,
-			"likes_count": post.LikesCount,
-			"user_id":     post.UserID,
-			"user_name":   userName,
+			"id":           post.ID,
+			"title":        post.Title,
+			"place_id":     post.SettlementID,
+			"place_name":   post.SettlementName,
+			"latitude":     post.Settlement.Latitude,
+			"longitude":    post.Settlement.Longitude,
+			"created_at":   post.CreatedAt,
+			"photos":       photoUrls,
+			"likes_count":  post.LikesCount,
+			"user_id":      post.UserID,
+			"user_name":    authors[post.UserID].Username,
+			"route":        stops,
+			"polyline":     polyline,
+			"distance_km":  post.RouteDistanceKm,
+			"visited_from": post.VisitedFrom,
+			"visited_to":   post.VisitedTo,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"posts": postMarkers,
-		"total": len(postMarkers),
+		"posts":       postMarkers,
+		"total":       len(postMarkers),
+		"next_cursor": nextCursor,
 	})
 }
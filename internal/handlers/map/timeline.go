@@ -0,0 +1,103 @@
+package maps
+
+import (
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetUserTimeline - хронология поездок пользователя для карты: где и когда он был.
+// Учитываются только посты с датами поездки; поддерживаются те же фильтры, что и у карты.
+func GetUserTimeline(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, username, image_url").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var posts []models.Post
+	if err := database.DB.
+		Where("user_id = ? AND is_approved = ?", userID, true).
+		Where("status = ?", models.PostStatusPublished).
+		Where("visited_from IS NOT NULL").
+		Scopes(visitFilter.Scope).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_approved = true").Order("\"order\" ASC")
+		}).
+		Preload("Settlement").
+		Preload("Stops", orderStops).
+		Preload("Stops.Settlement").
+		Order("visited_from ASC, visited_to ASC, id ASC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	// Поездки по годам начала - для шкалы времени на карте
+	years := make([]gin.H, 0)
+	yearIndex := make(map[int]int)
+
+	trips := make([]gin.H, 0, len(posts))
+	for _, post := range posts {
+		polyline, stops := tripRoute(post)
+
+		cover := ""
+		if len(post.Photos) > 0 {
+			cover = post.Photos[0].Url
+			if post.Photos[0].ThumbnailSmall != "" {
+				cover = post.Photos[0].ThumbnailSmall
+			}
+		}
+
+		year := post.VisitedFrom.Year()
+		if i, ok := yearIndex[year]; ok {
+			years[i]["trips_count"] = years[i]["trips_count"].(int) + 1
+		} else {
+			yearIndex[year] = len(years)
+			years = append(years, gin.H{"year": year, "trips_count": 1})
+		}
+
+		trips = append(trips, gin.H{
+			"id":           post.ID,
+			"title":        post.Title,
+			"slug":         post.Slug,
+			"visited_from": post.VisitedFrom,
+			"visited_to":   post.VisitedTo,
+			"place_id":     post.SettlementID,
+			"place_name":   post.SettlementName,
+			"latitude":     post.Settlement.Latitude,
+			"longitude":    post.Settlement.Longitude,
+			"cover":        cover,
+			"route":        stops,
+			"polyline":     polyline,
+			"distance_km":  post.RouteDistanceKm,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trips": trips,
+		"years": years,
+		"user": gin.H{
+			"id":       user.ID,
+			"username": user.Username,
+			"avatar":   user.ImageUrl,
+		},
+	})
+}
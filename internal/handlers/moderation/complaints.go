@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"padaroja/internal/domain/models"
 	database "padaroja/internal/storage/postgres"
+	"sort"
 	"strings"
 	"time"
 
@@ -76,7 +77,23 @@ func GetComplaints(c *gin.Context) {
 		Where("complaints.type = ? AND complaints.status IN (?, ?)",
 			models.ComplaintTypeComment, models.StatusNew, models.StatusProcessing)
 
-	unionQuery := database.DB.Raw("? UNION ? ORDER BY created_at DESC", postQuery, commentQuery)
+	reviewQuery := database.DB.Table("complaints").
+		Select(`
+			complaints.*,
+			COALESCE(settlements.name, '') as post_title,
+			COALESCE(reviews.text, '') as comment_content,
+			COALESCE(review_users.username, '') as author_username,
+			COALESCE(reviews.is_approved, true) as is_approved,
+			(SELECT COUNT(*) FROM complaints c2 WHERE c2.review_id = complaints.review_id 
+				AND c2.type = 'REVIEW' AND c2.status IN ('NEW', 'PROCESSING')) as complaint_count
+		`).
+		Joins("LEFT JOIN reviews ON reviews.id = complaints.review_id").
+		Joins("LEFT JOIN users review_users ON review_users.id = reviews.user_id").
+		Joins("LEFT JOIN settlements ON settlements.geonameid = reviews.settlement_id").
+		Where("complaints.type = ? AND complaints.status IN (?, ?)",
+			models.ComplaintTypeReview, models.StatusNew, models.StatusProcessing)
+
+	unionQuery := database.DB.Raw("? UNION ? UNION ? ORDER BY created_at DESC", postQuery, commentQuery, reviewQuery)
 
 	if err := unionQuery.Scan(&postComplaints).Error; err != nil {
 		fmt.Println("Database error fetching complaints:", err)
@@ -91,6 +108,7 @@ func GetComplaints(c *gin.Context) {
 			"type":            complaint.Type,
 			"post_id":         complaint.PostID,
 			"comment_id":      complaint.CommentID,
+			"review_id":       complaint.ReviewID,
 			"post_title":      complaint.PostTitle,
 			"comment_content": complaint.CommentContent,
 			"author":          complaint.AuthorUsername,
@@ -101,7 +119,7 @@ func GetComplaints(c *gin.Context) {
 			"created_at":      complaint.CreatedAt.Format(time.RFC3339),
 		}
 
-		if complaint.Type == models.ComplaintTypeComment {
+		if complaint.Type == models.ComplaintTypeComment || complaint.Type == models.ComplaintTypeReview {
 			if len(complaint.CommentContent) > 100 {
 				item["comment_content"] = complaint.CommentContent[:100] + "..."
 			}
@@ -110,6 +128,22 @@ func GetComplaints(c *gin.Context) {
 		result = append(result, item)
 	}
 
+	// Автоматические отметки о дублях разбираются в той же очереди
+	flags, err := duplicateFlagItems()
+	if err != nil {
+		fmt.Println("Database error fetching duplicate flags:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch complaints"})
+		return
+	}
+	if len(flags) > 0 {
+		result = append(result, flags...)
+		sort.SliceStable(result, func(i, j int) bool {
+			a, _ := time.Parse(time.RFC3339, result[i]["created_at"].(string))
+			b, _ := time.Parse(time.RFC3339, result[j]["created_at"].(string))
+			return a.After(b)
+		})
+	}
+
 	if result == nil {
 		result = []gin.H{}
 	}
@@ -249,6 +283,14 @@ func CreateCommentComplaint(c *gin.Context) {
 	})
 }
 
+// Статусы, которые модератор может выставить жалобе или отметке о дубле
+var validComplaintStatuses = map[models.ComplaintStatus]bool{
+	models.StatusNew:        true,
+	models.StatusProcessing: true,
+	models.StatusResolved:   true,
+	models.StatusRejected:   true,
+}
+
 func UpdateComplaintStatus(c *gin.Context) {
 	complaintID := c.Param("complaintID")
 
@@ -261,14 +303,7 @@ func UpdateComplaintStatus(c *gin.Context) {
 		return
 	}
 
-	validStatuses := map[models.ComplaintStatus]bool{
-		models.StatusNew:        true,
-		models.StatusProcessing: true,
-		models.StatusResolved:   true,
-		models.StatusRejected:   true,
-	}
-
-	if !validStatuses[request.Status] {
+	if !validComplaintStatuses[request.Status] {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
 		return
 	}
@@ -323,6 +358,9 @@ func TogglePostVisibility(c *gin.Context) {
 		database.DB.Model(&models.Complaint{}).
 			Where("post_id = ? AND status IN (?, ?)", postID, models.StatusNew, models.StatusProcessing).
 			Update("status", models.StatusResolved)
+		database.DB.Model(&models.DuplicateFlag{}).
+			Where("post_id = ? AND status IN (?, ?)", postID, models.StatusNew, models.StatusProcessing).
+			Update("status", models.StatusResolved)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -643,9 +681,11 @@ func GetUsersWithComplaints(c *gin.Context) {
         FROM users u
         LEFT JOIN posts p ON u.id = p.user_id
         LEFT JOIN comments cm ON u.id = cm.user_id
+        LEFT JOIN reviews r ON u.id = r.user_id
         LEFT JOIN complaints c ON (
             (c.post_id = p.id AND c.type = 'POST') OR 
-            (c.comment_id = cm.id AND c.type = 'COMMENT')
+            (c.comment_id = cm.id AND c.type = 'COMMENT') OR
+            (c.review_id = r.id AND c.type = 'REVIEW')
         )
         GROUP BY u.id, u.username, u.email, u.role_id, u.is_blocked
         HAVING COUNT(DISTINCT c.id) > 0
@@ -0,0 +1,128 @@
+package moderation
+
+import (
+	"encoding/json"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Тип элемента очереди для автоматических отметок о дублях
+const complaintTypeDuplicate = "DUPLICATE"
+
+// duplicateFlagItems - открытые отметки о почти одинаковых постах в формате очереди жалоб
+func duplicateFlagItems() ([]gin.H, error) {
+	var flags []struct {
+		models.DuplicateFlag
+		PostTitle      string
+		AuthorUsername string
+		IsApproved     bool
+	}
+	if err := database.DB.Table("duplicate_flags").
+		Select("duplicate_flags.*, posts.title AS post_title, users.username AS author_username, posts.is_approved").
+		Joins("JOIN posts ON posts.id = duplicate_flags.post_id AND posts.deleted_at IS NULL").
+		Joins("LEFT JOIN users ON users.id = posts.user_id").
+		Where("duplicate_flags.status IN (?, ?)", models.StatusNew, models.StatusProcessing).
+		Scan(&flags).Error; err != nil {
+		return nil, err
+	}
+
+	// Совпавшие посты всех отметок загружаются одним запросом
+	candidates := make([][]uint, len(flags))
+	var allIDs []uint
+	for i, flag := range flags {
+		json.Unmarshal([]byte(flag.MatchedPostIDs), &candidates[i])
+		allIDs = append(allIDs, candidates[i]...)
+	}
+
+	matchedPosts := make(map[uint]gin.H)
+	if len(allIDs) > 0 {
+		var posts []struct {
+			ID       uint
+			Title    string
+			Username string
+		}
+		if err := database.DB.Table("posts").
+			Select("posts.id, posts.title, users.username").
+			Joins("LEFT JOIN users ON users.id = posts.user_id").
+			Where("posts.id IN ? AND posts.deleted_at IS NULL", allIDs).
+			Scan(&posts).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range posts {
+			matchedPosts[p.ID] = gin.H{"id": p.ID, "title": p.Title, "author": p.Username}
+		}
+	}
+
+	items := make([]gin.H, 0, len(flags))
+	for i, flag := range flags {
+		// Совпавшие посты могли удалить после проверки
+		matched := make([]gin.H, 0, len(candidates[i]))
+		for _, id := range candidates[i] {
+			if p, ok := matchedPosts[id]; ok {
+				matched = append(matched, p)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		postID := flag.PostID
+		items = append(items, gin.H{
+			"id":              flag.ID,
+			"type":            complaintTypeDuplicate,
+			"post_id":         &postID,
+			"comment_id":      nil,
+			"post_title":      flag.PostTitle,
+			"comment_content": "",
+			"author":          flag.AuthorUsername,
+			"reason":          "Automatic: text closely matches earlier posts by other authors",
+			"status":          flag.Status,
+			"complaint_count": 0,
+			"is_approved":     flag.IsApproved,
+			"created_at":      flag.CreatedAt.Format(time.RFC3339),
+			"automatic":       true,
+			"similarity":      flag.Similarity,
+			"matched_posts":   matched,
+		})
+	}
+	return items, nil
+}
+
+// UpdateDuplicateFlagStatus - статус автоматической отметки о дубле. У отметок своя нумерация,
+// поэтому они обновляются отдельно от жалоб с тем же ID.
+func UpdateDuplicateFlagStatus(c *gin.Context) {
+	if !checkModeratorRights(c) {
+		return
+	}
+
+	var request struct {
+		Status models.ComplaintStatus `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !validComplaintStatuses[request.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	var flag models.DuplicateFlag
+	if err := database.DB.Where("id = ?", c.Param("flagID")).First(&flag).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Duplicate flag not found"})
+		return
+	}
+	if err := database.DB.Model(&flag).Update("status", request.Status).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update duplicate flag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Duplicate flag status updated",
+		"flag":    flag,
+	})
+}
@@ -0,0 +1,143 @@
+package moderation
+
+import (
+	"fmt"
+	"net/http"
+	"padaroja/internal/domain/models"
+	"padaroja/internal/handlers/review"
+	database "padaroja/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreateReviewComplaint(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var reviewID uint
+	if _, err := fmt.Sscanf(c.Param("reviewID"), "%d", &reviewID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason" binding:"required,min=10,max=500"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var target models.Review
+	if err := database.DB.Select("id").First(&target, reviewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var existingComplaint models.Complaint
+	err := database.DB.Where("user_id = ? AND review_id = ? AND type = ?",
+		userIDValue, reviewID, models.ComplaintTypeReview).
+		First(&existingComplaint).Error
+
+	if err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You have already reported this review"})
+		return
+	} else if err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	complaint := models.Complaint{
+		UserID:   userIDValue.(uint),
+		Type:     models.ComplaintTypeReview,
+		ReviewID: &reviewID,
+		Reason:   request.Reason,
+		Status:   models.StatusNew,
+	}
+
+	if err := database.DB.Create(&complaint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create complaint"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Complaint submitted successfully",
+		"complaint": complaint,
+	})
+}
+
+// ToggleReviewVisibility - скрыть или показать отзыв; скрытый отзыв не учитывается в оценке места
+func ToggleReviewVisibility(c *gin.Context) {
+	if !checkModeratorRights(c) {
+		return
+	}
+
+	reviewID := c.Param("reviewID")
+
+	var request struct {
+		IsApproved bool `json:"is_approved"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var target models.Review
+	if err := database.DB.Where("id = ?", reviewID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&target).Update("is_approved", request.IsApproved).Error; err != nil {
+			return err
+		}
+		return review.RecalculateSettlementRating(tx, target.SettlementID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review visibility"})
+		return
+	}
+
+	action := "shown"
+	if !request.IsApproved {
+		action = "hidden"
+		database.DB.Model(&models.Complaint{}).
+			Where("review_id = ? AND status IN (?, ?)", reviewID, models.StatusNew, models.StatusProcessing).
+			Update("status", models.StatusResolved)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review successfully " + action,
+		"review":  target,
+	})
+}
+
+func GetReviewComplaints(c *gin.Context) {
+	if !checkModeratorRights(c) {
+		return
+	}
+
+	reviewID := c.Param("reviewID")
+
+	var complaints []models.Complaint
+	if err := database.DB.
+		Where("review_id = ? AND type = ?", reviewID, models.ComplaintTypeReview).
+		Order("created_at DESC").
+		Find(&complaints).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review complaints"})
+		return
+	}
+
+	c.JSON(http.StatusOK, complaints)
+}
@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	"padaroja/internal/sse"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func getUserID(c *gin.Context) (int, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := userIDValue.(uint)
+	return int(userID), ok
+}
+
+// Create сохраняет уведомление и отправляет его в личный поток пользователя, если тот сейчас на сайте.
+// data - дополнительные поля для клиента (например, карточка поста); в БД они не сохраняются.
+func Create(db *gorm.DB, n *models.Notification, data gin.H) error {
+	if err := db.Create(n).Error; err != nil {
+		return err
+	}
+
+	payload := gin.H{"notification": n}
+	for key, value := range data {
+		payload[key] = value
+	}
+	message, _ := json.Marshal(gin.H{"type": n.Type, "data": payload})
+
+	go func() {
+		if sse.GlobalHub != nil {
+			sse.GlobalHub.NotifyUser <- sse.UserMessage{UserID: n.UserID, Data: message}
+		}
+	}()
+	return nil
+}
+
+// GetNotifications - уведомления пользователя, новые сначала; unread=true - только непрочитанные
+func GetNotifications(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC")
+	if c.Query("unread") == "true" {
+		query = query.Where("is_read = ?", false)
+	}
+	if page.Cursor != nil {
+		query = query.Where(utils.KeysetSQL("created_at", "id"), page.Cursor.Time, page.Cursor.ID)
+	}
+
+	var notifications []models.Notification
+	if err := query.Limit(page.Limit + 1).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	notifications, hasMore := utils.TrimPage(notifications, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := notifications[len(notifications)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Time: last.CreatedAt, ID: last.ID}))
+	}
+
+	var unreadCount int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&unreadCount)
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread_count":  unreadCount,
+		"next_cursor":   nextCursor,
+	})
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным
+func MarkNotificationRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	notificationID, err := strconv.ParseUint(c.Param("notificationID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	result := database.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя
+func MarkAllNotificationsRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Update("is_read", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
+
+// StreamNotifications - SSE-поток уведомлений вошедшего пользователя
+func StreamNotifications(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if sse.GlobalHub == nil {
+		log.Printf("⚠️ GlobalHub is nil, поток уведомлений недоступен")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Notifications stream unavailable"})
+		return
+	}
+
+	sse.GlobalHub.StreamNotifications(c.Writer, c.Request, userID)
+}
@@ -0,0 +1,219 @@
+package post
+
+import (
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальный период аналитики, дней
+const maxAnalyticsDays = 365
+
+// DailyPostStats - показатели поста за один день
+type DailyPostStats struct {
+	Date          string `json:"date"`
+	Views         int    `json:"views"`
+	UniqueReaders int    `json:"unique_readers"`
+	Likes         int    `json:"likes"`
+	Favourites    int    `json:"favourites"`
+	Comments      int    `json:"comments"`
+}
+
+// PostStatsTotals - показатели за период; уникальные читатели считаются за весь период, а не суммой по дням.
+// Отдельные просмотры хранятся VIEW_RETENTION_DAYS дней, поэтому уникальные читатели считаются не раньше unique_readers_from.
+type PostStatsTotals struct {
+	Views         int `json:"views"`
+	UniqueReaders int `json:"unique_readers"`
+	Likes         int `json:"likes"`
+	Favourites    int `json:"favourites"`
+	Comments      int `json:"comments"`
+}
+
+type PostAnalytics struct {
+	ID         uint              `json:"id"`
+	Title      string            `json:"title"`
+	Status     models.PostStatus `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ViewsCount int               `json:"views_count"` // за всё время
+	LikesCount int               `json:"likes_count"` // за всё время
+	Totals     PostStatsTotals   `json:"totals"`
+	Daily      []DailyPostStats  `json:"daily"`
+}
+
+type dailyCount struct {
+	PostID uint
+	Day    time.Time
+	Count  int
+}
+
+// countByDay - число строк таблицы (likes, favourites, comments) по постам и дням начиная с from
+func countByDay(table string, postIDs []uint, from time.Time) []dailyCount {
+	var counts []dailyCount
+	database.DB.Table(table).
+		Select("post_id, DATE(created_at) AS day, COUNT(*) AS count").
+		Where("post_id IN ? AND created_at >= ?", postIDs, from).
+		Group("post_id, DATE(created_at)").
+		Scan(&counts)
+	return counts
+}
+
+// GetPostsAnalytics - просмотры, уникальные читатели, лайки, избранное и комментарии по дням для постов автора.
+// Параметры: days (по умолчанию 30), post_id - только один пост.
+func GetPostsAnalytics(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxAnalyticsDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	// Полные дни, за которые ещё хранятся отдельные просмотры
+	readersFrom := today.AddDate(0, 0, -(int(viewRetention().Hours()/24) - 1))
+	if readersFrom.Before(from) {
+		readersFrom = from
+	}
+
+	query := database.DB.Select("id, title, status, created_at, views_count, likes_count").
+		Where("user_id = ?", userID)
+	if v := c.Query("post_id"); v != "" {
+		postID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID format"})
+			return
+		}
+		query = query.Where("id = ?", postID)
+	}
+
+	var posts []models.Post
+	if err := query.Order("created_at DESC").Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+	if c.Query("post_id") != "" && len(posts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	postIDs := make([]uint, 0, len(posts))
+	for _, p := range posts {
+		postIDs = append(postIDs, p.ID)
+	}
+
+	// Пустые дни тоже попадают в ряд, чтобы график не приходилось достраивать на клиенте
+	series := make(map[uint][]DailyPostStats, len(posts))
+	for _, id := range postIDs {
+		daily := make([]DailyPostStats, days)
+		for i := range daily {
+			daily[i].Date = from.AddDate(0, 0, i).Format("2006-01-02")
+		}
+		series[id] = daily
+	}
+	dayIndex := func(day time.Time) int {
+		d := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		return int(d.Sub(from).Hours() / 24)
+	}
+	at := func(postID uint, day time.Time) *DailyPostStats {
+		i := dayIndex(day)
+		daily := series[postID]
+		if i < 0 || i >= len(daily) {
+			return nil
+		}
+		return &daily[i]
+	}
+
+	uniqueReaders := make(map[uint]int, len(posts))
+	if len(postIDs) > 0 {
+		var stats []models.PostDailyStat
+		database.DB.Where("post_id IN ? AND day >= ?", postIDs, from).Find(&stats)
+		for _, s := range stats {
+			if d := at(s.PostID, s.Day); d != nil {
+				d.Views = s.Views
+				d.UniqueReaders = s.UniqueReaders
+			}
+		}
+
+		for _, row := range countByDay("likes", postIDs, from) {
+			if d := at(row.PostID, row.Day); d != nil {
+				d.Likes = row.Count
+			}
+		}
+		for _, row := range countByDay("favourites", postIDs, from) {
+			if d := at(row.PostID, row.Day); d != nil {
+				d.Favourites = row.Count
+			}
+		}
+		for _, row := range countByDay("comments", postIDs, from) {
+			if d := at(row.PostID, row.Day); d != nil {
+				d.Comments = row.Count
+			}
+		}
+
+		var readers []struct {
+			PostID uint
+			Count  int
+		}
+		database.DB.Model(&models.PostView{}).
+			Select("post_id, COUNT(DISTINCT viewer_key) AS count").
+			Where("post_id IN ? AND day >= ?", postIDs, readersFrom).
+			Group("post_id").
+			Scan(&readers)
+		for _, r := range readers {
+			uniqueReaders[r.PostID] = r.Count
+		}
+	}
+
+	var overall PostStatsTotals
+	response := make([]PostAnalytics, 0, len(posts))
+	for _, p := range posts {
+		totals := PostStatsTotals{UniqueReaders: uniqueReaders[p.ID]}
+		for _, d := range series[p.ID] {
+			totals.Views += d.Views
+			totals.Likes += d.Likes
+			totals.Favourites += d.Favourites
+			totals.Comments += d.Comments
+		}
+
+		overall.Views += totals.Views
+		overall.UniqueReaders += totals.UniqueReaders
+		overall.Likes += totals.Likes
+		overall.Favourites += totals.Favourites
+		overall.Comments += totals.Comments
+
+		response = append(response, PostAnalytics{
+			ID:         p.ID,
+			Title:      p.Title,
+			Status:     p.Status,
+			CreatedAt:  p.CreatedAt,
+			ViewsCount: p.ViewsCount,
+			LikesCount: p.LikesCount,
+			Totals:     totals,
+			Daily:      series[p.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":                from.Format("2006-01-02"),
+		"to":                  today.Format("2006-01-02"),
+		"days":                days,
+		"totals":              overall,
+		"posts":               response,
+		"unique_readers_from": readersFrom.Format("2006-01-02"),
+	})
+}
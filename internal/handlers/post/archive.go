@@ -0,0 +1,281 @@
+package post
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/internal/storage/uploads"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Версия формата архива; импорт отклоняет архивы более новых версий
+const archiveFormatVersion = 1
+
+// ArchiveManifest - manifest.json в корне архива
+type ArchiveManifest struct {
+	FormatVersion int       `json:"format_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	UserID        int       `json:"user_id"`
+	Username      string    `json:"username"`
+	PostsCount    int       `json:"posts_count"`
+}
+
+// ArchivePost - posts/<id>.json; населённые пункты указываются по geonameid
+type ArchivePost struct {
+	OriginalID     uint                 `json:"original_id"`
+	Title          string               `json:"title"`
+	Status         models.PostStatus    `json:"status"`
+	PublishAt      *time.Time           `json:"publish_at,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	SettlementID   uint                 `json:"settlement_id"`
+	SettlementName string               `json:"settlement_name"`
+	Paragraphs     []models.Paragraph   `json:"paragraphs"`
+	Photos         []ArchivePhoto       `json:"photos"`
+	Tags           []string             `json:"tags"`
+	Stops          []TripStopRequest    `json:"stops"`
+	Comments       []ArchiveComment     `json:"comments"`
+	LikesCount     int                  `json:"likes_count"`
+	Language       string               `json:"language,omitempty"`
+	Translations   []ArchiveTranslation `json:"translations,omitempty"`
+	VisitedFrom    *time.Time           `json:"visited_from,omitempty"`
+	VisitedTo      *time.Time           `json:"visited_to,omitempty"`
+}
+
+// ArchiveTranslation - перевод поста на другой язык
+type ArchiveTranslation struct {
+	Language   string             `json:"language"`
+	Title      string             `json:"title"`
+	Paragraphs []models.Paragraph `json:"paragraphs"`
+}
+
+// ArchivePhoto - фото поста; File - путь к файлу внутри архива (пусто для внешних ссылок)
+type ArchivePhoto struct {
+	Order       int    `json:"order"`
+	File        string `json:"file,omitempty"`
+	OriginalUrl string `json:"original_url"`
+	StopOrder   *int   `json:"stop_order,omitempty"`
+}
+
+type ArchiveComment struct {
+	OriginalID     uint      `json:"original_id"`
+	ParentID       *uint     `json:"parent_id,omitempty"`
+	AuthorID       int       `json:"author_id"`
+	AuthorUsername string    `json:"author_username"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ArchiveReaction - лайк или избранное пользователя (likes.json, favourites.json)
+type ArchiveReaction struct {
+	PostID         int       `json:"post_id"`
+	PostTitle      string    `json:"post_title"`
+	AuthorUsername string    `json:"author_username"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ExportUserArchive - ZIP-архив всех постов пользователя с фото, комментариями, лайками и избранным
+func ExportUserArchive(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, username").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var posts []models.Post
+	if err := database.DB.
+		Where("user_id = ?", userID).
+		Preload("Paragraphs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Preload("Tags").
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Order("created_at ASC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	filename := fmt.Sprintf("padaroja-%s-%s.zip", user.Username, time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	defer zw.Close()
+
+	// Заголовки уже отправлены, поэтому ошибки дальше только логируем
+	manifest := ArchiveManifest{
+		FormatVersion: archiveFormatVersion,
+		ExportedAt:    time.Now(),
+		UserID:        user.ID,
+		Username:      user.Username,
+		PostsCount:    len(posts),
+	}
+	if err := writeArchiveJSON(zw, "manifest.json", manifest); err != nil {
+		log.Printf("Ошибка экспорта для пользователя %d: %v", userID, err)
+		return
+	}
+
+	for _, post := range posts {
+		archivePost := ArchivePost{
+			OriginalID:     post.ID,
+			Title:          post.Title,
+			Status:         post.Status,
+			PublishAt:      post.PublishAt,
+			CreatedAt:      post.CreatedAt,
+			SettlementID:   post.SettlementID,
+			SettlementName: post.SettlementName,
+			Paragraphs:     post.Paragraphs,
+			Photos:         make([]ArchivePhoto, 0, len(post.Photos)),
+			Tags:           make([]string, 0, len(post.Tags)),
+			Stops:          make([]TripStopRequest, 0, len(post.Stops)),
+			Comments:       exportComments(post.ID),
+			LikesCount:     post.LikesCount,
+			Language:       postLanguage(post),
+			Translations:   exportTranslations(post.ID),
+			VisitedFrom:    post.VisitedFrom,
+			VisitedTo:      post.VisitedTo,
+		}
+		for _, tag := range post.Tags {
+			archivePost.Tags = append(archivePost.Tags, tag.Name)
+		}
+		for _, stop := range post.Stops {
+			archivePost.Stops = append(archivePost.Stops, TripStopRequest{
+				SettlementID:   stop.SettlementID,
+				SettlementName: stop.SettlementName,
+			})
+		}
+
+		for i, photo := range post.Photos {
+			archivePhoto := ArchivePhoto{
+				Order:       photo.Order,
+				OriginalUrl: photo.Url,
+				StopOrder:   photo.StopOrder,
+			}
+			// В архив кладём только наши загруженные файлы; внешние ссылки остаются ссылками
+			if localPath, ok := uploads.LocalPath(photo.Url); ok {
+				name := fmt.Sprintf("photos/%d/%d%s", post.ID, i+1, path.Ext(photo.Url))
+				if err := copyFileToArchive(zw, name, localPath); err != nil {
+					log.Printf("Фото %s не добавлено в архив: %v", photo.Url, err)
+				} else {
+					archivePhoto.File = name
+				}
+			}
+			archivePost.Photos = append(archivePost.Photos, archivePhoto)
+		}
+
+		if err := writeArchiveJSON(zw, fmt.Sprintf("posts/%d.json", post.ID), archivePost); err != nil {
+			log.Printf("Ошибка экспорта поста %d: %v", post.ID, err)
+			return
+		}
+	}
+
+	if err := writeArchiveJSON(zw, "likes.json", exportReactions("likes", int(userID))); err != nil {
+		log.Printf("Ошибка экспорта лайков: %v", err)
+		return
+	}
+	if err := writeArchiveJSON(zw, "favourites.json", exportReactions("favourites", int(userID))); err != nil {
+		log.Printf("Ошибка экспорта избранного: %v", err)
+		return
+	}
+
+	log.Printf("📦 Экспорт пользователя %d: %d постов", userID, len(posts))
+}
+
+func exportTranslations(postID uint) []ArchiveTranslation {
+	var translations []models.PostTranslation
+	database.DB.Where("post_id = ?", postID).Order("language ASC").Find(&translations)
+
+	result := make([]ArchiveTranslation, 0, len(translations))
+	for _, t := range translations {
+		result = append(result, ArchiveTranslation{
+			Language:   t.Language,
+			Title:      t.Title,
+			Paragraphs: decodeTranslationParagraphs(t),
+		})
+	}
+	return result
+}
+
+func exportComments(postID uint) []ArchiveComment {
+	var comments []models.Comment
+	database.DB.Where("post_id = ?", postID).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, username")
+		}).
+		Order("created_at ASC").
+		Find(&comments)
+
+	result := make([]ArchiveComment, 0, len(comments))
+	for _, comment := range comments {
+		result = append(result, ArchiveComment{
+			OriginalID:     comment.ID,
+			ParentID:       comment.ParentID,
+			AuthorID:       comment.UserID,
+			AuthorUsername: comment.User.Username,
+			Content:        comment.Content,
+			CreatedAt:      comment.CreatedAt,
+		})
+	}
+	return result
+}
+
+// exportReactions выгружает лайки (likes) или избранное (favourites) пользователя вместе с названием поста и автором
+func exportReactions(table string, userID int) []ArchiveReaction {
+	result := make([]ArchiveReaction, 0)
+	database.DB.Table(table).
+		Select(table+".post_id, posts.title AS post_title, users.username AS author_username, "+table+".created_at").
+		Joins("JOIN posts ON posts.id = "+table+".post_id AND posts.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = posts.user_id").
+		Where(table+".user_id = ?", userID).
+		Order(table + ".created_at ASC").
+		Scan(&result)
+	return result
+}
+
+func writeArchiveJSON(zw *zip.Writer, name string, value interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func copyFileToArchive(zw *zip.Writer, name, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Изображения уже сжаты - храним без повторного сжатия
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
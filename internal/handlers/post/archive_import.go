@@ -0,0 +1,446 @@
+package post
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/internal/storage/uploads"
+	"padaroja/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Ограничения импорта: JSON-файлы поста небольшие, фото ограничены лимитом загрузок
+const (
+	maxArchiveJSONBytes = 5 << 20
+	maxArchiveFiles     = 10000
+)
+
+var errPostAlreadyImported = errors.New("post already imported")
+
+// archiveMaxBytes - максимальный размер загружаемого архива
+func archiveMaxBytes() int64 {
+	if v := os.Getenv("ARCHIVE_MAX_MB"); v != "" {
+		if mb, err := strconv.Atoi(v); err == nil && mb > 0 {
+			return int64(mb) << 20
+		}
+	}
+	return 200 << 20
+}
+
+// ImportUserArchive - восстановление постов из архива ExportUserArchive (multipart, поле "archive").
+// Посты создаются от имени текущего пользователя; комментарии, лайки и избранное не импортируются.
+func ImportUserArchive(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	maxBytes := archiveMaxBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archive file is required"})
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read archive"})
+		return
+	}
+	defer file.Close()
+
+	zr, err := zip.NewReader(file, fileHeader.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ZIP archive"})
+		return
+	}
+	if len(zr.File) > maxArchiveFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archive contains too many files"})
+		return
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifestFile, ok := files["manifest.json"]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "manifest.json not found in archive"})
+		return
+	}
+	var manifest ArchiveManifest
+	if err := readArchiveJSON(manifestFile, &manifest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid manifest.json", "details": err.Error()})
+		return
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > archiveFormatVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported archive format version %d", manifest.FormatVersion)})
+		return
+	}
+
+	var posts []ArchivePost
+	for name, f := range files {
+		if !strings.HasPrefix(name, "posts/") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		var archivePost ArchivePost
+		if err := readArchiveJSON(f, &archivePost); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s", name), "details": err.Error()})
+			return
+		}
+		posts = append(posts, archivePost)
+	}
+
+	// Восстанавливаем в хронологическом порядке
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	results := make([]gin.H, 0, len(posts))
+	imported, skipped, failed := 0, 0, 0
+
+	for _, archivePost := range posts {
+		var newPost models.Post
+		var warnings []string
+		var stored []models.Upload
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			newPost, warnings, err = importArchivePost(tx, int(userID), archivePost, files, &stored)
+			return err
+		})
+		if err != nil {
+			removeOrphanUploads(stored)
+		}
+
+		result := gin.H{
+			"original_id": archivePost.OriginalID,
+			"title":       archivePost.Title,
+		}
+		if len(warnings) > 0 {
+			result["warnings"] = warnings
+		}
+
+		switch {
+		case errors.Is(err, errPostAlreadyImported):
+			skipped++
+			result["status"] = "skipped"
+			result["id"] = newPost.ID
+		case err != nil:
+			failed++
+			result["status"] = "failed"
+			result["error"] = err.Error()
+		default:
+			imported++
+			result["status"] = "imported"
+			result["id"] = newPost.ID
+		}
+		results = append(results, result)
+	}
+
+	log.Printf("📦 Импорт для пользователя %d: импортировано %d, пропущено %d, ошибок %d", userID, imported, skipped, failed)
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported": imported,
+		"skipped":  skipped,
+		"failed":   failed,
+		"posts":    results,
+	})
+}
+
+// removeOrphanUploads удаляет с диска файлы, записанные в откатившейся транзакции импорта.
+// Файл с тем же содержимым мог загрузить кто-то другой - такие файлы остаются.
+func removeOrphanUploads(stored []models.Upload) {
+	for _, upload := range stored {
+		var count int64
+		if err := database.DB.Model(&models.Upload{}).Where("hash = ?", upload.Hash).Count(&count).Error; err != nil || count > 0 {
+			continue
+		}
+		uploads.RemoveFiles(upload)
+	}
+}
+
+// importArchivePost создаёт один пост из архива; повторный импорт того же поста пропускается.
+// Загруженные фото добавляются в stored, чтобы при откате транзакции удалить их файлы.
+func importArchivePost(tx *gorm.DB, userID int, archivePost ArchivePost, files map[string]*zip.File, stored *[]models.Upload) (models.Post, []string, error) {
+	warnings := make([]string, 0)
+
+	title := strings.TrimSpace(archivePost.Title)
+	if title == "" {
+		return models.Post{}, nil, fmt.Errorf("title is required")
+	}
+
+	// Тот же автор, заголовок и дата создания - пост уже переносился
+	var existing models.Post
+	if err := tx.Where("user_id = ? AND title = ? AND created_at = ?", userID, title, archivePost.CreatedAt).
+		First(&existing).Error; err == nil {
+		return existing, nil, errPostAlreadyImported
+	}
+
+	settlementName, err := validateSettlement(tx, archivePost.SettlementID, utils.CleanSettlementName(archivePost.SettlementName))
+	if err != nil {
+		return models.Post{}, nil, err
+	}
+
+	status := archivePost.Status
+	publishAt := archivePost.PublishAt
+	switch status {
+	case models.PostStatusPublished, models.PostStatusDraft:
+		publishAt = nil
+	case models.PostStatusScheduled:
+		// Просроченная отложенная публикация будет выполнена фоновым публикатором
+		if publishAt == nil {
+			status = models.PostStatusDraft
+		}
+	default:
+		status = models.PostStatusPublished
+		publishAt = nil
+	}
+
+	// Фото: файлы из архива загружаем заново, внешние ссылки оставляем как есть
+	photos := make([]models.PostPhoto, 0, len(archivePost.Photos))
+	urlMapping := make(map[string]string)
+	for _, photo := range archivePost.Photos {
+		url := photo.OriginalUrl
+		if photo.File != "" {
+			f, ok := files[photo.File]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("photo file %s is missing", photo.File))
+				continue
+			}
+			data, err := readArchiveFile(f, uploads.MaxBytes)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("photo %s: %v", photo.File, err))
+				continue
+			}
+			upload, err := uploads.Store(tx, "photos", userID, data)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("photo %s: %v", photo.File, err))
+				continue
+			}
+			*stored = append(*stored, *upload)
+			url = upload.Url
+		}
+		if url == "" {
+			continue
+		}
+		urlMapping[photo.OriginalUrl] = url
+		photos = append(photos, models.PostPhoto{
+			Url:       url,
+			Order:     photo.Order,
+			StopOrder: photo.StopOrder,
+		})
+	}
+
+	// Блоки с фото ссылаются на новые адреса фото
+	paragraphs := archivePost.Paragraphs
+	remapPhotoBlocks(paragraphs, urlMapping)
+	if err := normalizeBlocks(paragraphs); err != nil {
+		return models.Post{}, nil, err
+	}
+
+	stops, distanceKm, err := buildTripStops(tx, archivePost.Stops)
+	if err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := validateStopReferences(len(stops), paragraphs, photos); err != nil {
+		return models.Post{}, nil, err
+	}
+
+	language := normalizeLanguage(archivePost.Language)
+	if language == "" {
+		language = supportedLanguages[0]
+	}
+
+	// Конец поездки без начала или раньше начала не сохраняем
+	visitedFrom, visitedTo := archivePost.VisitedFrom, archivePost.VisitedTo
+	if visitedFrom == nil || (visitedTo != nil && visitedTo.Before(*visitedFrom)) {
+		visitedTo = nil
+	}
+
+	newPost := models.Post{
+		UserID:         userID,
+		SettlementID:   archivePost.SettlementID,
+		SettlementName: settlementName,
+		Title:          title,
+		IsApproved:     true,
+		CreatedAt:      archivePost.CreatedAt,
+		Status:         status,
+		PublishAt:      publishAt,
+		Language:       language,
+		VisitedFrom:    visitedFrom,
+		VisitedTo:      visitedTo,
+	}
+	if newPost.Slug, err = uniquePostSlug(tx, userID, 0, utils.Slugify(title, language)); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := tx.Create(&newPost).Error; err != nil {
+		return models.Post{}, nil, err
+	}
+
+	if err := replacePostParagraphs(tx, newPost.ID, paragraphs); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := replacePostPhotos(tx, newPost.ID, photos); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := replacePostTags(tx, newPost.ID, archivePost.Tags); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := replaceTripStops(tx, newPost.ID, stops, distanceKm); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := validatePhotoBlocks(tx, newPost.ID); err != nil {
+		return models.Post{}, nil, err
+	}
+	if err := updatePostPreview(tx, newPost.ID); err != nil {
+		return models.Post{}, nil, err
+	}
+	if _, err := checkPostDuplicates(tx, newPost); err != nil {
+		return models.Post{}, nil, err
+	}
+	if _, err := createPostRevision(tx, newPost.ID, userID, nil); err != nil {
+		return models.Post{}, nil, err
+	}
+
+	// Переводы с ошибками пропускаем, чтобы не терять сам пост
+	seenLanguages := make(map[string]bool)
+	for _, t := range archivePost.Translations {
+		lang := normalizeLanguage(t.Language)
+		if seenLanguages[lang] {
+			warnings = append(warnings, fmt.Sprintf("translation %s is duplicated", t.Language))
+			continue
+		}
+		seenLanguages[lang] = true
+		if err := importArchiveTranslation(tx, newPost, userID, len(stops), t, urlMapping); err != nil {
+			warnings = append(warnings, fmt.Sprintf("translation %s: %v", t.Language, err))
+		}
+	}
+
+	// Поисковый документ собирается после переводов - они тоже ищутся
+	if err := updatePostSearchDocument(tx, newPost.ID); err != nil {
+		return models.Post{}, nil, err
+	}
+
+	if len(archivePost.Comments) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d comments were not imported", len(archivePost.Comments)))
+	}
+
+	return newPost, warnings, nil
+}
+
+func importArchiveTranslation(tx *gorm.DB, post models.Post, userID, stopCount int, t ArchiveTranslation, urlMapping map[string]string) error {
+	lang := normalizeLanguage(t.Language)
+	if lang == "" || lang == post.Language {
+		return fmt.Errorf("unsupported language")
+	}
+	title := strings.TrimSpace(t.Title)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if utf8.RuneCountInString(title) > maxTranslationTitleLength {
+		return fmt.Errorf("title must be at most %d characters", maxTranslationTitleLength)
+	}
+
+	paragraphs := t.Paragraphs
+	if paragraphs == nil {
+		paragraphs = []models.Paragraph{}
+	}
+	remapPhotoBlocks(paragraphs, urlMapping)
+	if err := normalizeBlocks(paragraphs); err != nil {
+		return err
+	}
+	if err := validateStopReferences(stopCount, paragraphs, nil); err != nil {
+		return err
+	}
+	if err := checkPhotoBlockUrls(tx, post.ID, paragraphs); err != nil {
+		return err
+	}
+	for i := range paragraphs {
+		paragraphs[i].ID = 0
+		paragraphs[i].PostID = post.ID
+	}
+	paragraphsJSON, err := json.Marshal(paragraphs)
+	if err != nil {
+		return err
+	}
+
+	translation := models.PostTranslation{
+		PostID:     post.ID,
+		Language:   lang,
+		Title:      title,
+		Paragraphs: string(paragraphsJSON),
+		UpdatedBy:  userID,
+	}
+	if err := setTranslationPreview(tx, &translation, paragraphs); err != nil {
+		return err
+	}
+	return tx.Create(&translation).Error
+}
+
+// remapPhotoBlocks заменяет в блоках photo адреса фото из архива на адреса заново загруженных файлов
+func remapPhotoBlocks(paragraphs []models.Paragraph, urlMapping map[string]string) {
+	for i := range paragraphs {
+		if paragraphs[i].Type != models.BlockPhoto {
+			continue
+		}
+		var d photoBlockData
+		if err := json.Unmarshal(paragraphs[i].Data, &d); err != nil {
+			continue
+		}
+		if newUrl, ok := urlMapping[d.Url]; ok {
+			d.Url = newUrl
+			paragraphs[i].Data, _ = json.Marshal(d)
+		}
+	}
+}
+
+func readArchiveJSON(f *zip.File, dst interface{}) error {
+	data, err := readArchiveFile(f, maxArchiveJSONBytes)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// readArchiveFile читает файл из архива, не доверяя заявленному размеру (защита от zip-бомб)
+func readArchiveFile(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("file is too large")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file is too large")
+	}
+	return data, nil
+}
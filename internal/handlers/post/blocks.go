@@ -0,0 +1,283 @@
+package post
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"padaroja/internal/domain/models"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Ограничения длины полей блоков (в символах)
+const (
+	maxBlockTextLength  = 10000
+	maxBlockShortLength = 200
+)
+
+// blockError - ошибка в содержимом блока, возвращается клиенту как 400
+type blockError struct {
+	Index int
+	Msg   string
+}
+
+func (e *blockError) Error() string {
+	return fmt.Sprintf("block %d: %s", e.Index+1, e.Msg)
+}
+
+// Данные блоков по типам
+type headingBlockData struct {
+	Level int `json:"level"`
+}
+
+type quoteBlockData struct {
+	Author string `json:"author,omitempty"`
+}
+
+type photoBlockData struct {
+	Url     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+}
+
+type mapPinBlockData struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Label     string   `json:"label,omitempty"`
+}
+
+type tipBlockData struct {
+	Title string `json:"title,omitempty"`
+}
+
+var (
+	htmlTagRe    = regexp.MustCompile(`(?s)<[^>]*>`)
+	mdImageRe    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	blankLinesRe = regexp.MustCompile(`\n{3,}`)
+
+	allowedLinkSchemes = []string{"http://", "https://", "mailto:"}
+)
+
+// sanitizeMarkdown оставляет безопасный inline markdown: убирает HTML, картинки (для них есть блок photo)
+// и ссылки с недопустимыми схемами вроде javascript:
+func sanitizeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = stripControlChars(s, true)
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = mdImageRe.ReplaceAllString(s, "$1")
+	s = sanitizeLinks(s)
+	s = blankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// sanitizePlain очищает однострочные поля: подписи, названия, авторов
+func sanitizePlain(s string) string {
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = stripControlChars(s, false)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func stripControlChars(s string, keepNewlines bool) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' && keepNewlines {
+			return r
+		}
+		if r == '\t' {
+			return ' '
+		}
+		if unicode.IsControl(r) {
+			if r == '\n' {
+				return ' '
+			}
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// decodeBlockData разбирает данные блока, неизвестные поля считаются ошибкой
+func decodeBlockData(data models.BlockData, dst interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
+
+// normalizeBlocks проверяет тип и данные каждого блока, очищает текст и приводит данные к каноничному виду
+func normalizeBlocks(paragraphs []models.Paragraph) error {
+	for i := range paragraphs {
+		p := &paragraphs[i]
+		if p.Type == "" {
+			p.Type = models.BlockText
+		}
+
+		fail := func(format string, args ...interface{}) error {
+			return &blockError{Index: i, Msg: fmt.Sprintf(format, args...)}
+		}
+
+		var data interface{}
+
+		switch p.Type {
+		case models.BlockText:
+			if len(p.Data) > 0 && string(p.Data) != "null" {
+				return fail("text block has no data")
+			}
+			p.Content = sanitizeMarkdown(p.Content)
+
+		case models.BlockHeading:
+			var d headingBlockData
+			if err := decodeBlockData(p.Data, &d); err != nil {
+				return fail("invalid heading data: %v", err)
+			}
+			if d.Level == 0 {
+				d.Level = 2
+			}
+			if d.Level < 2 || d.Level > 4 {
+				return fail("heading level must be between 2 and 4")
+			}
+			p.Content = sanitizePlain(p.Content)
+			if utf8.RuneCountInString(p.Content) > maxBlockShortLength {
+				return fail("heading is too long")
+			}
+			data = d
+
+		case models.BlockQuote:
+			var d quoteBlockData
+			if err := decodeBlockData(p.Data, &d); err != nil {
+				return fail("invalid quote data: %v", err)
+			}
+			d.Author = sanitizePlain(d.Author)
+			if utf8.RuneCountInString(d.Author) > maxBlockShortLength {
+				return fail("quote author is too long")
+			}
+			p.Content = sanitizeMarkdown(p.Content)
+			data = d
+
+		case models.BlockTip:
+			var d tipBlockData
+			if err := decodeBlockData(p.Data, &d); err != nil {
+				return fail("invalid tip data: %v", err)
+			}
+			d.Title = sanitizePlain(d.Title)
+			if utf8.RuneCountInString(d.Title) > maxBlockShortLength {
+				return fail("tip title is too long")
+			}
+			p.Content = sanitizeMarkdown(p.Content)
+			data = d
+
+		case models.BlockPhoto:
+			var d photoBlockData
+			if err := decodeBlockData(p.Data, &d); err != nil {
+				return fail("invalid photo data: %v", err)
+			}
+			d.Url = strings.TrimSpace(d.Url)
+			if d.Url == "" {
+				return fail("photo block requires url")
+			}
+			d.Caption = sanitizePlain(d.Caption)
+			if utf8.RuneCountInString(d.Caption) > maxBlockShortLength {
+				return fail("photo caption is too long")
+			}
+			// Подпись дублируется в content, чтобы блок участвовал в diff и поиске
+			p.Content = d.Caption
+			data = d
+
+		case models.BlockMapPin:
+			var d mapPinBlockData
+			if err := decodeBlockData(p.Data, &d); err != nil {
+				return fail("invalid map pin data: %v", err)
+			}
+			if d.Latitude == nil || d.Longitude == nil {
+				return fail("map pin requires latitude and longitude")
+			}
+			if *d.Latitude < -90 || *d.Latitude > 90 || *d.Longitude < -180 || *d.Longitude > 180 {
+				return fail("map pin coordinates are out of range")
+			}
+			d.Label = sanitizePlain(d.Label)
+			if utf8.RuneCountInString(d.Label) > maxBlockShortLength {
+				return fail("map pin label is too long")
+			}
+			p.Content = d.Label
+			data = d
+
+		default:
+			return fail("unknown block type %q", p.Type)
+		}
+
+		if utf8.RuneCountInString(p.Content) > maxBlockTextLength {
+			return fail("content is too long")
+		}
+		if p.Content == "" && p.Type != models.BlockPhoto && p.Type != models.BlockMapPin {
+			return fail("content is required")
+		}
+
+		if data != nil {
+			encoded, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			p.Data = encoded
+		} else {
+			p.Data = nil
+		}
+	}
+
+	return nil
+}
+
+// validatePhotoBlocks проверяет, что блоки photo ссылаются на фото этого поста
+func validatePhotoBlocks(tx *gorm.DB, postID uint) error {
+	var blocks []models.Paragraph
+	if err := tx.Where("post_id = ? AND type = ?", postID, models.BlockPhoto).
+		Order("\"order\" ASC").Find(&blocks).Error; err != nil {
+		return err
+	}
+	return checkPhotoBlockUrls(tx, postID, blocks)
+}
+
+// checkPhotoBlockUrls проверяет ссылки блоков photo по списку фото поста (используется и для переводов)
+func checkPhotoBlockUrls(tx *gorm.DB, postID uint, blocks []models.Paragraph) error {
+	hasPhotoBlocks := false
+	for _, block := range blocks {
+		if block.Type == models.BlockPhoto {
+			hasPhotoBlocks = true
+			break
+		}
+	}
+	if !hasPhotoBlocks {
+		return nil
+	}
+
+	var urls []string
+	if err := tx.Model(&models.PostPhoto{}).Where("post_id = ?", postID).Pluck("url", &urls).Error; err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		known[url] = true
+	}
+
+	for _, block := range blocks {
+		if block.Type != models.BlockPhoto {
+			continue
+		}
+		var d photoBlockData
+		if err := json.Unmarshal(block.Data, &d); err != nil || !known[d.Url] {
+			return &blockError{Index: block.Order - 1, Msg: fmt.Sprintf("photo %q is not attached to the post", d.Url)}
+		}
+	}
+	return nil
+}
+
+// blockSignature - представление блока для diff и определения изменённых полей
+func blockSignature(p models.Paragraph) string {
+	if p.Type == "" || p.Type == models.BlockText {
+		return p.Content
+	}
+	return fmt.Sprintf("[%s] %s %s", p.Type, p.Content, string(p.Data))
+}
@@ -0,0 +1,61 @@
+package post
+
+import (
+	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
+	database "padaroja/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedPostResponses собирает карточки ленты для страницы постов фиксированным числом запросов.
+// С localize заголовок и отрывок берутся из перевода на язык читателя, если он есть.
+func feedPostResponses(c *gin.Context, posts []models.Post, localize bool) ([]PostResponse, error) {
+	cards, err := feed.Cards(database.DB, posts)
+	if err != nil {
+		return nil, err
+	}
+	titles := map[uint]models.PostTranslation{}
+	if localize {
+		titles = localizedTitles(database.DB, c, posts)
+	}
+
+	response := make([]PostResponse, 0, len(posts))
+	for _, p := range posts {
+		card := cards[p.ID]
+
+		title, language := p.Title, postLanguage(p)
+		preview, words, minutes := p.PreviewText, p.WordCount, p.ReadingTimeMinutes
+		if translation, ok := titles[p.ID]; ok {
+			title, language = translation.Title, translation.Language
+			preview, words, minutes = translation.PreviewText, translation.WordCount, translation.ReadingTimeMinutes
+		}
+
+		response = append(response, PostResponse{
+			ID:             p.ID,
+			UserID:         uint(p.UserID),
+			Title:          title,
+			Date:           p.CreatedAt,
+			SettlementName: p.SettlementName,
+			SettlementID:   p.SettlementID,
+			Tags:           card.Tags,
+			Photos:         card.Photos(),
+			PhotosCount:    card.PhotosCount,
+			LikesCount:     p.LikesCount,
+			CommentsCount:  card.CommentsCount,
+			UserAvatar:     card.Author.ImageUrl,
+			UserName:       card.AuthorName(),
+			Status:         p.Status,
+			PublishAt:      p.PublishAt,
+			Language:       language,
+			Slug:           p.Slug,
+			VisitedFrom:    p.VisitedFrom,
+			VisitedTo:      p.VisitedTo,
+
+			PreviewText:        preview,
+			WordCount:          words,
+			ReadingTimeMinutes: minutes,
+		})
+	}
+	return response, nil
+}
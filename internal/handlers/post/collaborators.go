@@ -659,3 +659,36 @@ func GetSentInvites(c *gin.Context) {
 		"count":   len(result),
 	})
 }
+
+// collaboratorRole возвращает роль пользователя среди соавторов поста
+func collaboratorRole(db *gorm.DB, postID uint, userID int) (string, bool) {
+	var collaborator models.PostCollaborator
+	if err := db.Where("post_id = ? AND user_id = ?", postID, userID).First(&collaborator).Error; err != nil {
+		return "", false
+	}
+	return collaborator.Role, true
+}
+
+// isPostMember проверяет, является ли пользователь автором или соавтором поста
+func isPostMember(db *gorm.DB, post models.Post, userID int) bool {
+	if userID == 0 {
+		return false
+	}
+	if post.UserID == userID {
+		return true
+	}
+	_, ok := collaboratorRole(db, post.ID, userID)
+	return ok
+}
+
+// canEditPost проверяет, может ли пользователь редактировать пост: автор или соавтор с ролью editor
+func canEditPost(db *gorm.DB, post models.Post, userID int) bool {
+	if userID == 0 {
+		return false
+	}
+	if post.UserID == userID {
+		return true
+	}
+	role, ok := collaboratorRole(db, post.ID, userID)
+	return ok && role == "editor"
+}
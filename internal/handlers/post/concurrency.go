@@ -0,0 +1,195 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"padaroja/internal/domain/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errPostNotFound    = errors.New("post not found")
+	errEditForbidden   = errors.New("only the owner and editors can edit this post")
+	errStatusForbidden = errors.New("only the owner can change the post status")
+)
+
+// versionConflictError - пост был изменён другим редактором после версии, с которой работал клиент
+type versionConflictError struct {
+	CurrentVersion int
+	YourVersion    int
+	ChangedFields  []string // поля, изменённые другими редакторами после вашей версии
+	Conflicts      []string // поля, изменённые и вами, и другими редакторами
+	Current        *RevisionSnapshot
+}
+
+func (e *versionConflictError) Error() string {
+	return fmt.Sprintf("post version conflict: expected %d, current %d", e.YourVersion, e.CurrentVersion)
+}
+
+// response - тело ответа 409 для клиента
+func (e *versionConflictError) response() gin.H {
+	return gin.H{
+		"error":           "Post was modified by another editor",
+		"your_version":    e.YourVersion,
+		"current_version": e.CurrentVersion,
+		"changed_fields":  e.ChangedFields,
+		"conflicts":       e.Conflicts,
+		"current":         e.Current,
+	}
+}
+
+// postETag формирует ETag поста по номеру версии
+func postETag(version int) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// localizedPostETag формирует ETag поста на языке читателя. Переводы меняются без новой версии поста,
+// поэтому в ETag входят язык ответа, число языков и время последнего изменения переводов; версия идёт первой.
+func localizedPostETag(version int, language string, languages int, translatedAt time.Time) string {
+	var stamp int64
+	if !translatedAt.IsZero() {
+		stamp = translatedAt.UnixMilli()
+	}
+	return fmt.Sprintf("\"%d-%s-%d-%d\"", version, language, languages, stamp)
+}
+
+// expectedPostVersion возвращает версию, от которой редактировал клиент: из тела запроса или заголовка If-Match
+func expectedPostVersion(c *gin.Context, input PostUpdateRequest) (int, bool, error) {
+	if input.Version != nil {
+		return *input.Version, true, nil
+	}
+
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, false, nil
+	}
+
+	ifMatch = strings.TrimPrefix(ifMatch, "W/")
+	// ETag ответа на языке читателя начинается с версии: "3-en-2-1700000000000"
+	value, _, _ := strings.Cut(strings.Trim(ifMatch, "\""), "-")
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid If-Match header")
+	}
+	return version, true, nil
+}
+
+// detectVersionConflict сравнивает версию клиента с текущей и собирает конфликтующие поля
+func detectVersionConflict(tx *gorm.DB, post models.Post, expected int, input PostUpdateRequest) *versionConflictError {
+	if expected == post.Version {
+		return nil
+	}
+
+	conflict := &versionConflictError{
+		CurrentVersion: post.Version,
+		YourVersion:    expected,
+		ChangedFields:  []string{},
+		Conflicts:      []string{},
+	}
+
+	var currentRevision models.PostRevision
+	if err := tx.Where("post_id = ? AND version = ?", post.ID, post.Version).First(&currentRevision).Error; err != nil {
+		return conflict
+	}
+	current := revisionToSnapshot(currentRevision)
+	conflict.Current = &current
+
+	var baseRevision models.PostRevision
+	if err := tx.Where("post_id = ? AND version = ?", post.ID, expected).First(&baseRevision).Error; err != nil {
+		// Базовая версия неизвестна - считаем конфликтными все поля, которые клиент меняет
+		for _, field := range requestedFields(input, current) {
+			conflict.ChangedFields = append(conflict.ChangedFields, field)
+			conflict.Conflicts = append(conflict.Conflicts, field)
+		}
+		return conflict
+	}
+	base := revisionToSnapshot(baseRevision)
+
+	changedByOthers := changedSnapshotFields(base, current)
+	changedByClient := make(map[string]bool)
+	for _, field := range requestedFields(input, base) {
+		changedByClient[field] = true
+	}
+
+	for _, field := range changedByOthers {
+		conflict.ChangedFields = append(conflict.ChangedFields, field)
+		if changedByClient[field] {
+			conflict.Conflicts = append(conflict.Conflicts, field)
+		}
+	}
+
+	return conflict
+}
+
+// changedSnapshotFields возвращает поля, различающиеся между двумя версиями
+func changedSnapshotFields(from, to RevisionSnapshot) []string {
+	fields := make([]string, 0)
+	if from.Title != to.Title {
+		fields = append(fields, "title")
+	}
+	if from.SettlementID != to.SettlementID {
+		fields = append(fields, "settlement")
+	}
+	if !equalStrings(paragraphContents(from.Paragraphs), paragraphContents(to.Paragraphs)) {
+		fields = append(fields, "paragraphs")
+	}
+	if !equalStrings(photoUrls(from.Photos), photoUrls(to.Photos)) {
+		fields = append(fields, "photos")
+	}
+	if !equalStrings(from.Tags, to.Tags) {
+		fields = append(fields, "tags")
+	}
+	if !equalStrings(stopIDs(from.Stops), stopIDs(to.Stops)) {
+		fields = append(fields, "stops")
+	}
+	return fields
+}
+
+// requestedFields возвращает поля, которые запрос на обновление меняет относительно версии base
+func requestedFields(input PostUpdateRequest, base RevisionSnapshot) []string {
+	fields := make([]string, 0)
+	if input.Title != "" && input.Title != base.Title {
+		fields = append(fields, "title")
+	}
+	if input.SettlementID != 0 && input.SettlementID != base.SettlementID {
+		fields = append(fields, "settlement")
+	}
+	if len(input.Paragraphs) > 0 && !equalStrings(paragraphContents(input.Paragraphs), paragraphContents(base.Paragraphs)) {
+		fields = append(fields, "paragraphs")
+	}
+	if len(input.Photos) > 0 && !equalStrings(photoUrls(input.Photos), photoUrls(base.Photos)) {
+		fields = append(fields, "photos")
+	}
+	if len(input.Tags) > 0 && !equalStrings(input.Tags, base.Tags) {
+		fields = append(fields, "tags")
+	}
+	if input.Stops != nil && !equalStrings(requestedStopIDs(input.Stops), stopIDs(base.Stops)) {
+		fields = append(fields, "stops")
+	}
+	return fields
+}
+
+func photoUrls(photos []models.PostPhoto) []string {
+	urls := make([]string, 0, len(photos))
+	for _, p := range photos {
+		urls = append(urls, p.Url)
+	}
+	return urls
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
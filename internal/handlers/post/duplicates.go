@@ -0,0 +1,226 @@
+package post
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Короткие тексты слишком легко совпадают, их не сравниваем
+	minDuplicateWords = 40
+	// Оценка доли общих фрагментов текста, начиная с которой посты считаются почти одинаковыми
+	minDuplicateSimilarity = 0.7
+)
+
+// duplicateCandidate - пост, у которого совпала хотя бы одна часть подписи
+type duplicateCandidate struct {
+	PostID    uint
+	UserID    int
+	Signature string
+	Title     string
+	Slug      string
+	Status    models.PostStatus
+	CreatedAt time.Time
+}
+
+// fingerprintWords - слова текстовых блоков поста; метки на карте не учитываются
+func fingerprintWords(paragraphs []models.Paragraph) []string {
+	var words []string
+	for _, p := range paragraphs {
+		if p.Type == models.BlockMapPin {
+			continue
+		}
+		words = append(words, utils.TextWords(utils.MarkdownToPlain(p.Content))...)
+	}
+	return words
+}
+
+// savePostFingerprint пересчитывает подпись текста поста; у коротких текстов подпись удаляется
+func savePostFingerprint(tx *gorm.DB, post models.Post) ([]uint32, error) {
+	var paragraphs []models.Paragraph
+	if err := tx.Where("post_id = ?", post.ID).Order("paragraphs.order ASC").Find(&paragraphs).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostFingerprintBand{}).Error; err != nil {
+		return nil, err
+	}
+
+	words := fingerprintWords(paragraphs)
+	if len(words) < minDuplicateWords {
+		return nil, tx.Where("post_id = ?", post.ID).Delete(&models.PostFingerprint{}).Error
+	}
+
+	signature := utils.MinHash(words)
+	signatureJSON, err := json.Marshal(signature)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := models.PostFingerprint{
+		PostID:    post.ID,
+		UserID:    post.UserID,
+		Signature: string(signatureJSON),
+		UpdatedAt: time.Now(),
+	}
+	if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&fingerprint).Error; err != nil {
+		return nil, err
+	}
+
+	bandHashes := utils.MinHashBands(signature)
+	bands := make([]models.PostFingerprintBand, 0, len(bandHashes))
+	for i, hash := range bandHashes {
+		bands = append(bands, models.PostFingerprintBand{PostID: post.ID, Band: i, Hash: hash})
+	}
+	if err := tx.Create(&bands).Error; err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// checkPostDuplicates обновляет подпись поста и ищет почти одинаковые посты.
+// Свои совпадения возвращаются автору как предупреждения, совпадения с более ранними опубликованными постами
+// других авторов отмечаются для модераторов. Черновики модераторам не отправляются.
+func checkPostDuplicates(tx *gorm.DB, post models.Post) ([]gin.H, error) {
+	warnings := make([]gin.H, 0)
+
+	signature, err := savePostFingerprint(tx, post)
+	if err != nil {
+		return nil, err
+	}
+	if signature == nil {
+		return warnings, clearDuplicateFlag(tx, post.ID)
+	}
+
+	bandQuery := tx.Model(&models.PostFingerprintBand{}).Select("DISTINCT other.post_id").
+		Joins("JOIN post_fingerprint_bands other ON other.band = post_fingerprint_bands.band AND other.hash = post_fingerprint_bands.hash AND other.post_id <> post_fingerprint_bands.post_id").
+		Where("post_fingerprint_bands.post_id = ?", post.ID)
+
+	var candidates []duplicateCandidate
+	if err := tx.Table("post_fingerprints").
+		Select("post_fingerprints.post_id, post_fingerprints.user_id, post_fingerprints.signature, posts.title, posts.slug, posts.status, posts.created_at").
+		Joins("JOIN posts ON posts.id = post_fingerprints.post_id AND posts.deleted_at IS NULL").
+		Where("post_fingerprints.post_id IN (?)", bandQuery).
+		Scan(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var matchedIDs []uint
+	maxSimilarity := 0.0
+	for _, candidate := range candidates {
+		var other []uint32
+		if err := json.Unmarshal([]byte(candidate.Signature), &other); err != nil {
+			continue
+		}
+		similarity := utils.MinHashSimilarity(signature, other)
+		if similarity < minDuplicateSimilarity {
+			continue
+		}
+		similarity = math.Round(similarity*100) / 100
+
+		if candidate.UserID == post.UserID {
+			warnings = append(warnings, gin.H{
+				"type":       "possible_duplicate",
+				"post_id":    candidate.PostID,
+				"title":      candidate.Title,
+				"slug":       candidate.Slug,
+				"status":     candidate.Status,
+				"similarity": similarity,
+			})
+			continue
+		}
+
+		// Оригиналом считается более ранний опубликованный пост
+		if candidate.Status == models.PostStatusPublished && candidate.CreatedAt.Before(post.CreatedAt) {
+			matchedIDs = append(matchedIDs, candidate.PostID)
+			maxSimilarity = math.Max(maxSimilarity, similarity)
+		}
+	}
+
+	if len(matchedIDs) == 0 || post.Status == models.PostStatusDraft {
+		return warnings, clearDuplicateFlag(tx, post.ID)
+	}
+	return warnings, raiseDuplicateFlag(tx, post.ID, matchedIDs, maxSimilarity)
+}
+
+// raiseDuplicateFlag создаёт или обновляет отметку модераторам. Отметка, которую модератор уже разобрал,
+// снова открывается только при появлении новых совпадений.
+func raiseDuplicateFlag(tx *gorm.DB, postID uint, matchedIDs []uint, similarity float64) error {
+	sort.Slice(matchedIDs, func(i, j int) bool { return matchedIDs[i] < matchedIDs[j] })
+	idsJSON, err := json.Marshal(matchedIDs)
+	if err != nil {
+		return err
+	}
+
+	var flag models.DuplicateFlag
+	err = tx.Where("post_id = ?", postID).First(&flag).Error
+	if err == gorm.ErrRecordNotFound {
+		return tx.Create(&models.DuplicateFlag{
+			PostID:         postID,
+			MatchedPostIDs: string(idsJSON),
+			Similarity:     similarity,
+			Status:         models.StatusNew,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	var previous []uint
+	json.Unmarshal([]byte(flag.MatchedPostIDs), &previous)
+	known := make(map[uint]bool, len(previous))
+	for _, id := range previous {
+		known[id] = true
+	}
+	status := flag.Status
+	for _, id := range matchedIDs {
+		if !known[id] {
+			status = models.StatusNew
+			break
+		}
+	}
+
+	return tx.Model(&flag).Updates(map[string]interface{}{
+		"matched_post_ids": string(idsJSON),
+		"similarity":       similarity,
+		"status":           status,
+	}).Error
+}
+
+// clearDuplicateFlag снимает неразобранную отметку, если текст поста больше ни с чем не совпадает
+func clearDuplicateFlag(tx *gorm.DB, postID uint) error {
+	return tx.Where("post_id = ? AND status IN ?", postID, []models.ComplaintStatus{models.StatusNew, models.StatusProcessing}).
+		Delete(&models.DuplicateFlag{}).Error
+}
+
+// BackfillPostFingerprints считает подписи постов, созданных до появления проверки на дубли.
+// Отметки модераторам для старых постов не создаются.
+func BackfillPostFingerprints() {
+	var posts []models.Post
+	if err := database.DB.Select("id, user_id").
+		Where("id NOT IN (SELECT post_id FROM post_fingerprints)").
+		Where("word_count >= ?", minDuplicateWords).
+		Order("id ASC").
+		Find(&posts).Error; err != nil {
+		log.Printf("Ошибка выборки постов без подписи: %v", err)
+		return
+	}
+
+	for _, post := range posts {
+		if _, err := savePostFingerprint(database.DB, post); err != nil {
+			log.Printf("Не удалось посчитать подпись поста %d: %v", post.ID, err)
+		}
+	}
+	if len(posts) > 0 {
+		log.Printf("🔍 Посчитаны подписи для %d постов", len(posts))
+	}
+}
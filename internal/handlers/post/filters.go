@@ -0,0 +1,180 @@
+package post
+
+import (
+	"fmt"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Наибольший радиус поиска вокруг точки, км
+const maxFeedRadiusKm = 1000
+
+// feedFilter - фильтры ленты по месту, автору, фото, лайкам и тегам.
+// Место поста - основной населённый пункт или любая остановка маршрута.
+type feedFilter struct {
+	Admin1Code string // область (admin1_code GeoNames)
+	Admin2Code string // район внутри области
+
+	HasCenter bool
+	Lat, Lon  float64
+	RadiusKm  float64
+
+	AuthorID   int
+	AuthorName string
+	HasPhotos  bool
+	MinLikes   int
+
+	Tags    string
+	TagsAny bool
+}
+
+// parseFeedFilter читает фильтр из параметров запроса:
+// admin1_code, admin2_code, radius_km вместе с lat и lon или near_settlement_id,
+// author_id или author (имя пользователя), has_photos, min_likes, tags и tags_mode (all - по умолчанию, any).
+func parseFeedFilter(query func(string) string) (feedFilter, error) {
+	var filter feedFilter
+
+	filter.Admin1Code = strings.TrimSpace(query("admin1_code"))
+	filter.Admin2Code = strings.TrimSpace(query("admin2_code"))
+	if filter.Admin2Code != "" && filter.Admin1Code == "" {
+		// Коды районов уникальны только внутри области
+		return filter, fmt.Errorf("admin2_code requires admin1_code")
+	}
+
+	latValue, lonValue := strings.TrimSpace(query("lat")), strings.TrimSpace(query("lon"))
+	settlementValue := strings.TrimSpace(query("near_settlement_id"))
+	switch {
+	case settlementValue != "":
+		if latValue != "" || lonValue != "" {
+			return filter, fmt.Errorf("use either lat/lon or near_settlement_id")
+		}
+		settlementID, err := strconv.ParseUint(settlementValue, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid near_settlement_id")
+		}
+		var settlement models.Settlement
+		if err := database.DB.Select("geonameid, latitude, longitude").First(&settlement, settlementID).Error; err != nil {
+			return filter, fmt.Errorf("settlement %d not found", settlementID)
+		}
+		filter.HasCenter, filter.Lat, filter.Lon = true, settlement.Latitude, settlement.Longitude
+	case latValue != "" || lonValue != "":
+		lat, latErr := strconv.ParseFloat(latValue, 64)
+		lon, lonErr := strconv.ParseFloat(lonValue, 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			return filter, fmt.Errorf("invalid lat/lon")
+		}
+		filter.HasCenter, filter.Lat, filter.Lon = true, lat, lon
+	}
+
+	if v := strings.TrimSpace(query("radius_km")); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 || radius > maxFeedRadiusKm {
+			return filter, fmt.Errorf("invalid radius_km, expected 0-%d", maxFeedRadiusKm)
+		}
+		if !filter.HasCenter {
+			return filter, fmt.Errorf("radius_km requires lat/lon or near_settlement_id")
+		}
+		filter.RadiusKm = radius
+	} else if filter.HasCenter {
+		return filter, fmt.Errorf("radius_km is required with lat/lon or near_settlement_id")
+	}
+
+	if v := strings.TrimSpace(query("author_id")); v != "" {
+		authorID, err := strconv.Atoi(v)
+		if err != nil || authorID <= 0 {
+			return filter, fmt.Errorf("invalid author_id")
+		}
+		filter.AuthorID = authorID
+	}
+	filter.AuthorName = strings.TrimSpace(query("author"))
+
+	if v := strings.TrimSpace(query("has_photos")); v != "" {
+		hasPhotos, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid has_photos, expected true or false")
+		}
+		filter.HasPhotos = hasPhotos
+	}
+
+	if v := strings.TrimSpace(query("min_likes")); v != "" {
+		minLikes, err := strconv.Atoi(v)
+		if err != nil || minLikes < 0 {
+			return filter, fmt.Errorf("invalid min_likes")
+		}
+		filter.MinLikes = minLikes
+	}
+
+	filter.Tags = query("tags")
+	switch mode := strings.ToLower(strings.TrimSpace(query("tags_mode"))); mode {
+	case "", "all":
+	case "any":
+		filter.TagsAny = true
+	default:
+		return filter, fmt.Errorf("invalid tags_mode %q, expected all or any", mode)
+	}
+
+	return filter, nil
+}
+
+// placeScope оставляет посты, у которых основной населённый пункт или остановка маршрута подходят под условие на settlements
+func placeScope(condition string, args ...interface{}) func(*gorm.DB) *gorm.DB {
+	settlements := "SELECT geonameid FROM settlements WHERE " + condition
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(posts.settlement_id IN ("+settlements+") OR "+
+			"posts.id IN (SELECT post_id FROM trip_stops WHERE settlement_id IN ("+settlements+")))",
+			append(append([]interface{}{}, args...), args...)...)
+	}
+}
+
+// Scope - условия фильтра для запроса по таблице posts
+func (f feedFilter) Scope(db *gorm.DB) *gorm.DB {
+	if f.Admin1Code != "" {
+		if f.Admin2Code != "" {
+			db = db.Scopes(placeScope("admin1_code = ? AND admin2_code = ?", f.Admin1Code, f.Admin2Code))
+		} else {
+			db = db.Scopes(placeScope("admin1_code = ?", f.Admin1Code))
+		}
+	}
+
+	if f.RadiusKm > 0 {
+		minLat, maxLat, minLon, maxLon := utils.BoundingBox(f.Lat, f.Lon, f.RadiusKm)
+		db = db.Scopes(placeScope(
+			"latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? AND "+utils.HaversineSQL("latitude", "longitude")+" <= ?",
+			minLat, maxLat, minLon, maxLon, f.Lat, f.Lat, f.Lon, f.RadiusKm,
+		))
+	}
+
+	if f.AuthorID != 0 {
+		db = db.Where("posts.user_id = ?", f.AuthorID)
+	}
+	if f.AuthorName != "" {
+		db = db.Where("posts.user_id IN (SELECT id FROM users WHERE username = ?)", f.AuthorName)
+	}
+
+	if f.HasPhotos {
+		db = db.Where("EXISTS (SELECT 1 FROM post_photos WHERE post_photos.post_id = posts.id AND post_photos.is_approved = true)")
+	}
+	if f.MinLikes > 0 {
+		db = db.Where("posts.likes_count >= ?", f.MinLikes)
+	}
+
+	if f.Tags != "" {
+		db = applyTagFilter(db, f.Tags, f.TagsAny)
+	}
+	return db
+}
+
+// feedFilterScope - фильтр ленты из параметров запроса, как utils.VisitFilter.Scope
+func feedFilterScope(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+	filter, err := parseFeedFilter(c.Query)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Scope, nil
+}
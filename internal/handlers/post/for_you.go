@@ -0,0 +1,306 @@
+package post
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Коды причин рекомендации в ответе "для вас"
+const (
+	ReasonSettlementAffinity = "settlement_affinity" // пользователь лайкал или сохранял посты об этом месте
+	ReasonFollowedAuthor     = "followed_author"     // автор в подписках
+	ReasonPreferredTags      = "preferred_tags"      // теги, которые часто встречаются в понравившихся постах
+	ReasonFresh              = "fresh"               // пост недавно опубликован
+	ReasonPopular            = "popular"             // у поста много лайков
+)
+
+const (
+	// Сколько самых частых тегов из понравившихся постов учитывается
+	forYouPreferredTags = 20
+	// Насыщение составляющих: больше стольких совпадений не добавляет веса
+	forYouSettlementSaturation = 5
+	forYouTagSaturation        = 3
+	// Лайков, при которых популярность достигает максимума
+	forYouPopularityLikes = 100
+)
+
+// ForYouWeights - веса составляющих ленты "для вас"
+type ForYouWeights struct {
+	Settlement float64 `json:"settlement"`
+	Follow     float64 `json:"follow"`
+	Tags       float64 `json:"tags"`
+	Freshness  float64 `json:"freshness"`
+	Popularity float64 `json:"popularity"`
+}
+
+func envWeight(name string, def float64) float64 {
+	if v := os.Getenv(name); v != "" {
+		if w, err := strconv.ParseFloat(v, 64); err == nil && w >= 0 {
+			return w
+		}
+	}
+	return def
+}
+
+// forYouWeights читает веса из окружения (FOR_YOU_WEIGHT_*); нулевой вес отключает составляющую
+func forYouWeights() ForYouWeights {
+	return ForYouWeights{
+		Settlement: envWeight("FOR_YOU_WEIGHT_SETTLEMENT", 3),
+		Follow:     envWeight("FOR_YOU_WEIGHT_FOLLOW", 3),
+		Tags:       envWeight("FOR_YOU_WEIGHT_TAGS", 2),
+		Freshness:  envWeight("FOR_YOU_WEIGHT_FRESHNESS", 1),
+		Popularity: envWeight("FOR_YOU_WEIGHT_POPULARITY", 1),
+	}
+}
+
+// forYouFreshnessHalfLifeHours - за сколько часов свежесть поста падает вдвое
+func forYouFreshnessHalfLifeHours() float64 {
+	if v := os.Getenv("FOR_YOU_FRESHNESS_HALF_LIFE_HOURS"); v != "" {
+		if h, err := strconv.ParseFloat(v, 64); err == nil && h > 0 {
+			return h
+		}
+	}
+	return 72
+}
+
+// forYouMaxAgeDays - посты старше не попадают в кандидаты, чтобы не ранжировать весь архив
+func forYouMaxAgeDays() int {
+	if v := os.Getenv("FOR_YOU_MAX_AGE_DAYS"); v != "" {
+		if d, err := strconv.Atoi(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return 365
+}
+
+// RecommendationReason - машиночитаемое объяснение, почему пост в ленте.
+// Contribution - вклад составляющей в итоговую оценку (вес × значение от 0 до 1).
+type RecommendationReason struct {
+	Code         string   `json:"code"`
+	Contribution float64  `json:"contribution"`
+	SettlementID *uint    `json:"settlement_id,omitempty"`
+	AuthorID     *int     `json:"author_id,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// ForYouPostResponse - пост ленты "для вас" с оценкой и причинами, самая весомая причина первой
+type ForYouPostResponse struct {
+	PostRecommendationResponse
+	Score   float64                `json:"score"`
+	Reasons []RecommendationReason `json:"reasons"`
+}
+
+// forYouRow - составляющие оценки поста, каждая от 0 до 1
+type forYouRow struct {
+	ID         uint
+	Settlement float64
+	Follow     float64
+	TagMatch   float64
+	Freshness  float64
+	Popularity float64
+	Score      int64 // итоговая оценка × 1e6, целым числом для курсора
+}
+
+// GetForYouRecommendations - единая персональная лента: близость мест, подписки, любимые теги, свежесть и популярность.
+// Исключаются свои посты и уже лайкнутые или сохранённые. Параметры: фильтры дат поездки, cursor, limit.
+func GetForYouRecommendations(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, 50)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if page.Cursor != nil && page.Cursor.Sort != "for_you" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Свежесть считается от загрузки первой страницы, чтобы порядок не сдвигался при листании
+	asOf := time.Now()
+	if page.Cursor != nil && page.Cursor.AsOf != nil {
+		asOf = *page.Cursor.AsOf
+	}
+
+	// Любимые теги - самые частые среди лайкнутых и сохранённых постов
+	var preferredTagIDs []uint
+	database.DB.Table("post_tags").
+		Select("post_tags.tag_id").
+		Where("post_tags.post_id IN (SELECT post_id FROM likes WHERE user_id = ? UNION SELECT post_id FROM favourites WHERE user_id = ?)", userID, userID).
+		Group("post_tags.tag_id").
+		Order("COUNT(*) DESC, post_tags.tag_id").
+		Limit(forYouPreferredTags).
+		Pluck("post_tags.tag_id", &preferredTagIDs)
+	tagIDs := preferredTagIDs
+	if len(tagIDs) == 0 {
+		tagIDs = []uint{0}
+	}
+
+	// Составляющие оценки, каждая приведена к диапазону 0..1
+	components := database.DB.Table("posts").
+		Select(`posts.id,
+			LEAST(COALESCE(place_affinity.n, 0), ?)::float / ? AS settlement,
+			CASE WHEN followed.followed_id IS NULL THEN 0 ELSE 1 END AS follow,
+			LEAST(tag_match.n, ?)::float / ? AS tag_match,
+			POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - posts.created_at)), 0) / 3600.0 / ?) AS freshness,
+			LEAST(LN(1 + GREATEST(posts.likes_count, 0)) / LN(?), 1) AS popularity`,
+			forYouSettlementSaturation, forYouSettlementSaturation,
+			forYouTagSaturation, forYouTagSaturation,
+			asOf, forYouFreshnessHalfLifeHours(),
+			1+forYouPopularityLikes).
+		Joins(`LEFT JOIN (
+			SELECT liked_posts.settlement_id, COUNT(*) AS n
+			FROM posts liked_posts
+			WHERE liked_posts.settlement_id > 0 AND liked_posts.id IN (
+				SELECT post_id FROM likes WHERE user_id = ? UNION SELECT post_id FROM favourites WHERE user_id = ?
+			)
+			GROUP BY liked_posts.settlement_id
+		) place_affinity ON place_affinity.settlement_id = posts.settlement_id`, userID, userID).
+		Joins("LEFT JOIN followers followed ON followed.followed_id = posts.user_id AND followed.follower_id = ?", userID).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS n FROM post_tags WHERE post_tags.post_id = posts.id AND post_tags.tag_id IN ?
+		) tag_match ON true`, tagIDs).
+		Where("posts.deleted_at IS NULL").
+		Where("posts.is_approved = ? AND posts.status = ?", true, models.PostStatusPublished).
+		Where("posts.created_at <= ? AND posts.created_at > ?", asOf, asOf.AddDate(0, 0, -forYouMaxAgeDays())).
+		Where("posts.user_id != ?", userID).
+		Where("posts.id NOT IN (SELECT post_id FROM likes WHERE user_id = ?)", userID).
+		Where("posts.id NOT IN (SELECT post_id FROM favourites WHERE user_id = ?)", userID).
+		Scopes(visitFilter.Scope)
+
+	weights := forYouWeights()
+	scored := database.DB.Table("(?) AS components", components).
+		Select(`components.*, ((? * settlement + ? * follow + ? * tag_match + ? * freshness + ? * popularity) * 1000000)::bigint AS score`,
+			weights.Settlement, weights.Follow, weights.Tags, weights.Freshness, weights.Popularity)
+
+	ranked := database.DB.Table("(?) AS ranked", scored)
+	if page.Cursor != nil {
+		ranked = ranked.Where(utils.KeysetSQL("ranked.score", "ranked.id"), page.Cursor.Score, page.Cursor.ID)
+	}
+
+	var rows []forYouRow
+	if err := ranked.Order("ranked.score DESC, ranked.id DESC").Limit(page.Limit + 1).Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, hasMore := utils.TrimPage(rows, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := rows[len(rows)-1]
+		nextCursor = utils.StringPtr(utils.EncodeCursor(utils.Cursor{Sort: "for_you", Score: last.Score, ID: last.ID, AsOf: &asOf}))
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var found []models.Post
+	if len(ids) > 0 {
+		if err := database.DB.Preload("Settlement").Where("id IN ?", ids).Find(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	postByID := make(map[uint]models.Post, len(found))
+	for _, p := range found {
+		postByID[p.ID] = p
+	}
+	posts := make([]models.Post, 0, len(rows))
+	for _, row := range rows {
+		if p, ok := postByID[row.ID]; ok {
+			posts = append(posts, p)
+		}
+	}
+
+	cards, err := formatRecommendationResponse(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var preferredNames []string
+	if len(preferredTagIDs) > 0 {
+		database.DB.Model(&models.Tags{}).Where("id IN ?", preferredTagIDs).Pluck("name", &preferredNames)
+	}
+	preferred := make(map[string]bool, len(preferredNames))
+	for _, name := range preferredNames {
+		preferred[name] = true
+	}
+
+	rowByID := make(map[uint]forYouRow, len(rows))
+	for _, row := range rows {
+		rowByID[row.ID] = row
+	}
+	response := make([]ForYouPostResponse, 0, len(cards))
+	for i, card := range cards {
+		row := rowByID[card.ID]
+		response = append(response, ForYouPostResponse{
+			PostRecommendationResponse: card,
+			Score:                      float64(row.Score) / 1000000,
+			Reasons:                    forYouReasons(row, weights, posts[i], card.Tags, preferred),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       response,
+		"type":        "for_you",
+		"weights":     weights,
+		"next_cursor": nextCursor,
+	})
+}
+
+// forYouReasons раскладывает оценку поста на причины с ненулевым вкладом, по убыванию вклада
+func forYouReasons(row forYouRow, weights ForYouWeights, post models.Post, tags []string, preferred map[string]bool) []RecommendationReason {
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+
+	var reasons []RecommendationReason
+	if v := weights.Settlement * row.Settlement; v > 0 {
+		settlementID := post.SettlementID
+		reasons = append(reasons, RecommendationReason{Code: ReasonSettlementAffinity, Contribution: round(v), SettlementID: &settlementID})
+	}
+	if v := weights.Follow * row.Follow; v > 0 {
+		authorID := post.UserID
+		reasons = append(reasons, RecommendationReason{Code: ReasonFollowedAuthor, Contribution: round(v), AuthorID: &authorID})
+	}
+	if v := weights.Tags * row.TagMatch; v > 0 {
+		var matched []string
+		for _, tag := range tags {
+			if preferred[tag] {
+				matched = append(matched, tag)
+			}
+		}
+		reasons = append(reasons, RecommendationReason{Code: ReasonPreferredTags, Contribution: round(v), Tags: matched})
+	}
+	if v := weights.Freshness * row.Freshness; v > 0 {
+		reasons = append(reasons, RecommendationReason{Code: ReasonFresh, Contribution: round(v)})
+	}
+	if v := weights.Popularity * row.Popularity; v > 0 {
+		reasons = append(reasons, RecommendationReason{Code: ReasonPopular, Contribution: round(v)})
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].Contribution > reasons[j].Contribution })
+	if reasons == nil {
+		reasons = []RecommendationReason{}
+	}
+	return reasons
+}
@@ -0,0 +1,98 @@
+package post
+
+import (
+	"log"
+	"os"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Вес событий в оценке актуальности: сохранение и комментарий значат больше лайка, просмотр - меньше
+const (
+	hotWeightLike      = 1.0
+	hotWeightComment   = 2.0
+	hotWeightFavourite = 3.0
+	hotWeightView      = 0.1
+)
+
+// Через столько периодов полураспада вклад события меньше 0,1% - старые события не читаем
+const hotHalfLivesWindow = 10
+
+// Эпоха оценок актуальности - момент последнего пересчёта, изменившего hot_score (UnixNano).
+// Пересчёт идёт одним UPDATE, поэтому в пределах эпохи порядок "актуальных" неизменен.
+var hotScoreEpoch atomic.Int64
+
+// currentHotScoreEpoch - эпоха, от которой считаны текущие оценки актуальности
+func currentHotScoreEpoch() time.Time {
+	return time.Unix(0, hotScoreEpoch.Load()).UTC()
+}
+
+// hotScoreHalfLife - за сколько вклад события в оценку актуальности падает вдвое
+func hotScoreHalfLife() time.Duration {
+	if v := os.Getenv("HOT_SCORE_HALF_LIFE_HOURS"); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 {
+			return time.Duration(hours * float64(time.Hour))
+		}
+	}
+	return 24 * time.Hour
+}
+
+// RunHotScoreUpdater периодически пересчитывает оценку актуальности постов (posts.hot_score).
+// Сортировка "актуальные" и запасные варианты рекомендаций читают готовую оценку вместо подсчёта лайков на каждый запрос.
+func RunHotScoreUpdater(interval time.Duration) {
+	updateHotScores()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		updateHotScores()
+	}
+}
+
+// updateHotScores - сумма событий поста (лайки, комментарии, сохранения, просмотры) с экспоненциальным затуханием:
+// событие возрастом t весит weight × 0.5^(t / период полураспада). Переписываются только изменившиеся строки.
+func updateHotScores() {
+	now := time.Now()
+	halfLife := hotScoreHalfLife()
+	halfLifeHours := halfLife.Hours()
+	cutoff := now.Add(-hotHalfLivesWindow * halfLife)
+
+	// Просмотры хранятся по дням; считаем их в середине дня
+	result := database.DB.Exec(`
+		WITH events AS (
+			SELECT post_id, created_at AS at, ?::float AS weight FROM likes WHERE created_at > ?
+			UNION ALL
+			SELECT post_id, created_at, ?::float FROM comments WHERE created_at > ? AND is_approved = true
+			UNION ALL
+			SELECT post_id, created_at, ?::float FROM favourites WHERE created_at > ?
+			UNION ALL
+			SELECT post_id, day + INTERVAL '12 hours', ?::float * views FROM post_daily_stats WHERE day > ?::date
+		),
+		scores AS (
+			SELECT post_id, (SUM(weight * POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - at)), 0) / 3600.0 / ?)) * 1000000)::bigint AS score
+			FROM events
+			GROUP BY post_id
+		)
+		UPDATE posts SET hot_score = COALESCE(scores.score, 0)
+		FROM posts AS target
+		LEFT JOIN scores ON scores.post_id = target.id
+		WHERE posts.id = target.id AND posts.hot_score <> COALESCE(scores.score, 0)
+	`,
+		hotWeightLike, cutoff,
+		hotWeightComment, cutoff,
+		hotWeightFavourite, cutoff,
+		hotWeightView, cutoff,
+		now, halfLifeHours,
+	)
+	if result.Error != nil {
+		log.Printf("Ошибка пересчёта оценки актуальности: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		hotScoreEpoch.Store(now.UnixNano())
+		log.Printf("🔥 Оценка актуальности обновлена для %d постов за %v", result.RowsAffected, time.Since(now).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,196 @@
+package post
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Радиус поиска ближайших населённых пунктов вокруг точки съёмки, км
+const nearbySearchRadiusKm = 50.0
+
+// SettlementSuggestion - населённый пункт рядом с местом съёмки
+type SettlementSuggestion struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// settlementWarnDistanceKm - расстояние от места съёмки до выбранного пункта, после которого CreatePost предупреждает автора
+func settlementWarnDistanceKm() float64 {
+	if v := os.Getenv("SETTLEMENT_WARN_DISTANCE_KM"); v != "" {
+		if km, err := strconv.ParseFloat(v, 64); err == nil && km > 0 {
+			return km
+		}
+	}
+	return 25
+}
+
+// nearestSettlements возвращает ближайшие к точке населённые пункты, отсортированные по расстоянию
+func nearestSettlements(db *gorm.DB, lat, lon float64, limit int) ([]SettlementSuggestion, error) {
+	// Прямоугольник отсекает далёкие пункты по индексу, точное расстояние считается в запросе
+	minLat, maxLat, minLon, maxLon := utils.BoundingBox(lat, lon, nearbySearchRadiusKm)
+	distanceSQL := utils.HaversineSQL("latitude", "longitude")
+
+	var settlements []models.Settlement
+	err := db.Select("geonameid, name, alternatenames, latitude, longitude").
+		Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLon, maxLon).
+		Where(distanceSQL+" <= ?", lat, lat, lon, nearbySearchRadiusKm).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                distanceSQL,
+			Vars:               []interface{}{lat, lat, lon},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&settlements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]SettlementSuggestion, 0, len(settlements))
+	for _, s := range settlements {
+		name := utils.ExtractRussianName(s.Alternatenames)
+		if name == "" {
+			name = s.Name
+		}
+		suggestions = append(suggestions, SettlementSuggestion{
+			ID:         s.Geonameid,
+			Name:       name,
+			Latitude:   s.Latitude,
+			Longitude:  s.Longitude,
+			DistanceKm: math.Round(utils.HaversineKm(lat, lon, s.Latitude, s.Longitude)*10) / 10,
+		})
+	}
+
+	return suggestions, nil
+}
+
+// photoLocations возвращает координаты съёмки для фото, загруженных этим пользователем.
+// Чужие загрузки не учитываются, чтобы по ссылке нельзя было узнать место съёмки.
+func photoLocations(db *gorm.DB, userID int, urls []string) map[string][2]float64 {
+	locations := make(map[string][2]float64)
+	if len(urls) == 0 {
+		return locations
+	}
+
+	var uploads []models.Upload
+	if err := db.Select("url, latitude, longitude").
+		Where("url IN ? AND user_id = ? AND latitude IS NOT NULL AND longitude IS NOT NULL", urls, userID).
+		Find(&uploads).Error; err != nil {
+		return locations
+	}
+
+	for _, u := range uploads {
+		locations[u.Url] = [2]float64{*u.Latitude, *u.Longitude}
+	}
+	return locations
+}
+
+// checkPhotoLocations сравнивает место съёмки каждого фото с выбранным населённым пунктом
+// (или остановкой поездки, к которой привязано фото) и возвращает предупреждения для далёких фото
+func checkPhotoLocations(db *gorm.DB, userID int, settlementID uint, stops []TripStopRequest, photos []models.PostPhoto) []gin.H {
+	warnings := make([]gin.H, 0)
+
+	locations := photoLocations(db, userID, photoUrls(photos))
+	if len(locations) == 0 {
+		return warnings
+	}
+
+	threshold := settlementWarnDistanceKm()
+	settlementCoords := make(map[uint]*models.Settlement)
+
+	for _, photo := range photos {
+		location, ok := locations[photo.Url]
+		if !ok {
+			continue
+		}
+
+		targetID := settlementID
+		if photo.StopOrder != nil && *photo.StopOrder >= 1 && *photo.StopOrder <= len(stops) {
+			targetID = stops[*photo.StopOrder-1].SettlementID
+		}
+
+		target, ok := settlementCoords[targetID]
+		if !ok {
+			var s models.Settlement
+			if err := db.Select("geonameid, latitude, longitude").First(&s, "geonameid = ?", targetID).Error; err == nil {
+				target = &s
+			}
+			settlementCoords[targetID] = target
+		}
+		if target == nil {
+			continue
+		}
+
+		distance := utils.HaversineKm(location[0], location[1], target.Latitude, target.Longitude)
+		if distance <= threshold {
+			continue
+		}
+
+		suggestions, _ := nearestSettlements(db, location[0], location[1], 3)
+		warnings = append(warnings, gin.H{
+			"type":          "photo_far_from_settlement",
+			"photo_url":     photo.Url,
+			"settlement_id": targetID,
+			"distance_km":   math.Round(distance*10) / 10,
+			"suggestions":   suggestions,
+		})
+	}
+
+	return warnings
+}
+
+// SuggestSettlements - ближайшие населённые пункты по координатам (?lat=&lon=)
+// или по месту съёмки своих загруженных фото (?photo=url, можно несколько)
+func SuggestSettlements(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var lat, lon float64
+	if photos := c.QueryArray("photo"); len(photos) > 0 {
+		locations := photoLocations(database.DB, int(userID), photos)
+		if len(locations) == 0 {
+			c.JSON(http.StatusOK, gin.H{"location": nil, "results": []SettlementSuggestion{}})
+			return
+		}
+		// Берём центр всех точек съёмки
+		for _, location := range locations {
+			lat += location[0]
+			lon += location[1]
+		}
+		lat /= float64(len(locations))
+		lon /= float64(len(locations))
+	} else {
+		var errLat, errLon error
+		lat, errLat = strconv.ParseFloat(c.Query("lat"), 64)
+		lon, errLon = strconv.ParseFloat(c.Query("lon"), 64)
+		if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lon or photo parameters are required"})
+			return
+		}
+	}
+
+	suggestions, err := nearestSettlements(database.DB, lat, lon, 5)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find settlements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"location": gin.H{"latitude": lat, "longitude": lon},
+		"results":  suggestions,
+	})
+}
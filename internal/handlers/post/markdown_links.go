@@ -0,0 +1,201 @@
+package post
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Определение ссылки для ссылок-сносок ([текст][метка]): "[метка]: адрес заголовок", адрес может быть на следующей строке
+var mdLinkDefinitionRe = regexp.MustCompile(`(?m)^ {0,3}\[(?:[^\]\\\n]|\\.)+\]:[ \t]*\n?[ \t]*(\S+)[^\n]*$`)
+
+// linkDestinationAllowed проверяет адрес ссылки так, как его увидит браузер после рендера markdown:
+// с раскрытыми escape-последовательностями и HTML-сущностями и без пробельных символов.
+// Разрешены http(s), mailto и относительные пути от корня сайта.
+func linkDestinationAllowed(dest string) bool {
+	dest = strings.TrimSpace(dest)
+	if strings.HasPrefix(dest, "<") && strings.HasSuffix(dest, ">") {
+		dest = dest[1 : len(dest)-1]
+	}
+	dest = unescapeMarkdown(dest)
+	dest = html.UnescapeString(dest)
+	dest = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, dest)
+	dest = strings.ToLower(dest)
+
+	for _, prefix := range allowedLinkSchemes {
+		if strings.HasPrefix(dest, prefix) {
+			return true
+		}
+	}
+	// "//host" и "/\host" браузер считает адресом другого сайта
+	return strings.HasPrefix(dest, "/") && !strings.HasPrefix(dest, "//") && !strings.HasPrefix(dest, `/\`)
+}
+
+// Знаки, которые в markdown экранируются обратной косой чертой
+const markdownPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// unescapeMarkdown убирает обратную косую черту перед знаками препинания, как это делает рендер
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(markdownPunctuation, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// sanitizeLinks заменяет текстом inline-ссылки с недопустимым адресом и удаляет такие определения ссылок-сносок.
+// Адрес разбирается по правилам CommonMark: пробелы после скобки, адрес в <...>, вложенные скобки и заголовок.
+func sanitizeLinks(s string) string {
+	s = mdLinkDefinitionRe.ReplaceAllStringFunc(s, func(def string) string {
+		dest := mdLinkDefinitionRe.FindStringSubmatch(def)[1]
+		if linkDestinationAllowed(dest) {
+			return def
+		}
+		return ""
+	})
+
+	for pos := 0; ; {
+		idx := strings.Index(s[pos:], "](")
+		if idx < 0 {
+			break
+		}
+		closeBracket := pos + idx
+		open := linkTextStart(s, closeBracket)
+		if open < 0 {
+			pos = closeBracket + 2
+			continue
+		}
+
+		dest, end, wellFormed := parseInlineDestination(s, closeBracket+2)
+		if wellFormed && linkDestinationAllowed(dest) {
+			pos = end
+			continue
+		}
+		if !wellFormed && (strings.TrimSpace(dest) == "" || linkDestinationAllowed(dest)) {
+			// Не ссылка - рендер покажет текст как есть
+			pos = closeBracket + 2
+			continue
+		}
+
+		text := s[open+1 : closeBracket]
+		s = s[:open] + text + s[end:]
+		pos = open + len(text)
+	}
+	return s
+}
+
+// linkTextStart находит открывающую скобку текста ссылки для "]" в позиции closeBracket с учётом вложенных скобок.
+// Текст ссылки не выходит за пределы абзаца; -1 - скобка не найдена.
+func linkTextStart(s string, closeBracket int) int {
+	depth := 0
+	for i := closeBracket - 1; i >= 0; i-- {
+		if i > 0 && s[i-1] == '\\' {
+			continue
+		}
+		switch s[i] {
+		case ']':
+			depth++
+		case '[':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '\n':
+			if i > 0 && s[i-1] == '\n' {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// parseInlineDestination разбирает "(адрес заголовок)" начиная сразу после "(".
+// Возвращает адрес, позицию после закрывающей ")" и признак того, что конструкция разобрана полностью.
+// Для неполной конструкции end указывает на конец адреса.
+func parseInlineDestination(s string, start int) (dest string, end int, wellFormed bool) {
+	i := skipLinkSpace(s, start)
+
+	destStart := i
+	if i < len(s) && s[i] == '<' {
+		for i++; i < len(s) && s[i] != '>' && s[i] != '\n'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) || s[i] != '>' {
+			i = min(i, len(s))
+			return s[destStart:i], i, false
+		}
+		i++
+	} else {
+		depth := 0
+	dest:
+		for ; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\':
+				i++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break dest
+				}
+				depth--
+			case c <= ' ':
+				break dest
+			}
+		}
+	}
+	if i > len(s) {
+		i = len(s)
+	}
+	dest = s[destStart:i]
+
+	i = skipLinkSpace(s, i)
+	if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		closing := s[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		for i++; i < len(s) && s[i] != closing; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return dest, destStart + len(dest), false
+		}
+		i = skipLinkSpace(s, i+1)
+	}
+	if i < len(s) && s[i] == ')' {
+		return dest, i + 1, true
+	}
+	return dest, destStart + len(dest), false
+}
+
+// skipLinkSpace пропускает пробелы и не более одного перевода строки
+func skipLinkSpace(s string, i int) int {
+	newline := false
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t':
+		case '\n':
+			if newline {
+				return i
+			}
+			newline = true
+		default:
+			return i
+		}
+	}
+	return i
+}
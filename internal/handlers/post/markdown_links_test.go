@@ -0,0 +1,49 @@
+package post
+
+import "testing"
+
+func TestSanitizeMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"https link kept", "[сайт](https://example.com)", "[сайт](https://example.com)"},
+		{"mailto kept", "[почта](mailto:a@example.com)", "[почта](mailto:a@example.com)"},
+		{"site path kept", "[пост](/post/1)", "[пост](/post/1)"},
+		{"title kept", `[сайт](https://example.com "Заголовок")`, `[сайт](https://example.com "Заголовок")`},
+		{"nested parens kept", "[вики](https://example.com/a_(b))", "[вики](https://example.com/a_(b))"},
+		{"javascript", "[x](javascript:alert(1))", "x"},
+		{"leading space", "[x]( javascript:alert(1))", "x"},
+		{"newline before destination", "[x](\njavascript:alert(1))", "x"},
+		{"with title", `[x](javascript:alert(1) "t")`, "x"},
+		{"with single-quoted title", "[x](javascript:alert(1) 't')", "x"},
+		{"with paren title", "[x](javascript:alert(1) (t))", "x"},
+		{"upper case scheme", "[x](JavaScript:alert(1))", "x"},
+		{"entity in scheme", "[x](javascript&#58;alert(1))", "x"},
+		{"named entity in scheme", "[x](javascript&colon;alert(1))", "x"},
+		{"escaped colon", `[x](javascript\:alert(1))`, "x"},
+		{"data uri", "[x](data:text/html;base64,PHNjcmlwdD4=)", "x"},
+		{"protocol relative", "[x](//evil.example)", "x"},
+		{"backslash host", `[x](/\evil.example)`, "x"},
+		{"empty destination", "[x]()", "x"},
+		{"text around", "до [x](javascript:alert(1)) после", "до x после"},
+		{"nested link text", "[[a](javascript:x)](https://example.com)", "[a](https://example.com)"},
+		{"reference definition", "[x][r]\n\n[r]: javascript:alert(1)", "[x][r]"},
+		{"reference definition with title", "[x][r]\n\n[r]: javascript:alert(1) \"t\"", "[x][r]"},
+		{"reference definition on next line", "[x][r]\n\n[r]:\n  javascript:alert(1)", "[x][r]"},
+		{"indented reference definition", "[r]\n\n   [r]: JAVASCRIPT:alert(1)", "[r]"},
+		{"allowed reference definition", "[x][r]\n\n[r]: https://example.com", "[x][r]\n\n[r]: https://example.com"},
+		{"not a link", "[x] (javascript:alert(1))", "[x] (javascript:alert(1))"},
+		{"unclosed allowed link", "[x](https://example.com", "[x](https://example.com"},
+		{"unclosed javascript link", "[x](javascript:alert(1) y", "x y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeMarkdown(tt.in); got != tt.want {
+				t.Errorf("sanitizeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
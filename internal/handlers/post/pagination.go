@@ -0,0 +1,28 @@
+package post
+
+import (
+	"padaroja/internal/domain/models"
+	"padaroja/utils"
+
+	"gorm.io/gorm"
+)
+
+// newestFirst сортирует посты от новых к старым и продолжает выборку после курсора
+func newestFirst(page utils.Page) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order("posts.created_at DESC, posts.id DESC")
+		if page.Cursor != nil {
+			db = db.Where(utils.KeysetSQL("posts.created_at", "posts.id"), page.Cursor.Time, page.Cursor.ID)
+		}
+		return db.Limit(page.Limit + 1)
+	}
+}
+
+// newestFirstCursor - курсор следующей страницы для newestFirst; nil, если страница последняя
+func newestFirstCursor(posts []models.Post, hasMore bool) *string {
+	if !hasMore || len(posts) == 0 {
+		return nil
+	}
+	last := posts[len(posts)-1]
+	return utils.StringPtr(utils.EncodeCursor(utils.Cursor{Sort: "new", Time: last.CreatedAt, ID: last.ID}))
+}
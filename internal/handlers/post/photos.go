@@ -0,0 +1,60 @@
+package post
+
+import (
+	"padaroja/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// attachUploadMetadata заполняет размеры и миниатюры фото по загрузкам; значения от клиента не принимаются
+func attachUploadMetadata(tx *gorm.DB, photos []models.PostPhoto) error {
+	if len(photos) == 0 {
+		return nil
+	}
+
+	urls := make([]string, 0, len(photos))
+	for i := range photos {
+		photos[i].Width = 0
+		photos[i].Height = 0
+		photos[i].ThumbnailSmall = ""
+		photos[i].ThumbnailMedium = ""
+		photos[i].ThumbnailLarge = ""
+		urls = append(urls, photos[i].Url)
+	}
+
+	var uploads []models.Upload
+	if err := tx.Where("url IN ?", urls).Find(&uploads).Error; err != nil {
+		return err
+	}
+
+	byUrl := make(map[string]models.Upload, len(uploads))
+	for _, u := range uploads {
+		byUrl[u.Url] = u
+	}
+
+	for i := range photos {
+		u, ok := byUrl[photos[i].Url]
+		if !ok {
+			continue // внешняя ссылка или старое фото - оставляем как есть
+		}
+		photos[i].Width = u.Width
+		photos[i].Height = u.Height
+		photos[i].ThumbnailSmall = u.ThumbnailSmall
+		photos[i].ThumbnailMedium = u.ThumbnailMedium
+		photos[i].ThumbnailLarge = u.ThumbnailLarge
+	}
+
+	return nil
+}
+
+// feedPhotos подменяет оригиналы миниатюрами для лент; оригинал отдаётся на странице поста
+func feedPhotos(photos []models.PostPhoto) []models.PostPhoto {
+	result := make([]models.PostPhoto, len(photos))
+	for i, p := range photos {
+		if p.ThumbnailMedium != "" {
+			p.Url = p.ThumbnailMedium
+		}
+		result[i] = p
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package post
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -18,6 +19,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostCreationRequest struct {
@@ -27,7 +29,13 @@ type PostCreationRequest struct {
 	Tags           []string           `json:"tags"`
 	Paragraphs     []models.Paragraph `json:"paragraphs"`
 	Photos         []models.PostPhoto `json:"photos"`
-	Invites        []InviteRequest    `json:"invites"` // НОВОЕ
+	Invites        []InviteRequest    `json:"invites"`      // НОВОЕ
+	Status         models.PostStatus  `json:"status"`       // draft, scheduled, published (по умолчанию)
+	PublishAt      *time.Time         `json:"publish_at"`   // обязателен для scheduled
+	Stops          []TripStopRequest  `json:"stops"`        // остановки поездки по порядку
+	Language       string             `json:"language"`     // язык оригинала: ru (по умолчанию), be, en
+	VisitedFrom    string             `json:"visited_from"` // даты поездки, YYYY-MM-DD
+	VisitedTo      string             `json:"visited_to"`
 }
 
 type InviteRequest struct {
@@ -43,10 +51,22 @@ type PostResponse struct {
 	SettlementName string             `json:"settlement_name"`
 	SettlementID   uint               `json:"settlement_id"`
 	Tags           []string           `json:"tags"`
-	Photos         []models.PostPhoto `json:"photos"`
+	Photos         []models.PostPhoto `json:"photos"` // в ленте - только обложка
+	PhotosCount    int                `json:"photos_count"`
 	LikesCount     int                `json:"likes_count"`
+	CommentsCount  int                `json:"comments_count"`
 	UserAvatar     string             `json:"user_avatar"`
 	UserName       string             `json:"user_name"`
+	Status         models.PostStatus  `json:"status"`
+	PublishAt      *time.Time         `json:"publish_at,omitempty"`
+	Language       string             `json:"language"`
+	Slug           string             `json:"slug"`
+	VisitedFrom    *time.Time         `json:"visited_from,omitempty"`
+	VisitedTo      *time.Time         `json:"visited_to,omitempty"`
+
+	PreviewText        string `json:"preview_text"`
+	WordCount          int    `json:"word_count"`
+	ReadingTimeMinutes int    `json:"reading_time_minutes"`
 }
 
 type DetailPostResponse struct {
@@ -58,10 +78,25 @@ type DetailPostResponse struct {
 	SettlementID     uint               `json:"settlement_id"`
 	Tags             []string           `json:"tags"`
 	PreviewText      string             `json:"preview_text"`
+	WordCount        int                `json:"word_count"`
+	ReadingTime      int                `json:"reading_time_minutes"`
 	Paragraphs       []models.Paragraph `json:"paragraphs"`
 	Photos           []models.PostPhoto `json:"photos"`
 	LikesCount       int                `json:"likes_count"`
 	CommentsDisabled bool               `json:"comments_disabled"`
+	Status           models.PostStatus  `json:"status"`
+	PublishAt        *time.Time         `json:"publish_at,omitempty"`
+	Version          int                `json:"version"`
+	Stops            []models.TripStop  `json:"stops"`
+	RouteDistanceKm  float64            `json:"route_distance_km"`
+	Slug             string             `json:"slug"`
+	VisitedFrom      *time.Time         `json:"visited_from,omitempty"`
+	VisitedTo        *time.Time         `json:"visited_to,omitempty"`
+
+	// Язык, на котором отданы заголовок и параграфы; оригинал и все доступные переводы
+	Language           string   `json:"language"`
+	OriginalLanguage   string   `json:"original_language"`
+	AvailableLanguages []string `json:"available_languages"`
 }
 
 type PostUpdateRequest struct {
@@ -71,6 +106,13 @@ type PostUpdateRequest struct {
 	Tags           []string           `json:"tags"`
 	Paragraphs     []models.Paragraph `json:"paragraphs"`
 	Photos         []models.PostPhoto `json:"photos"`
+	Status         *models.PostStatus `json:"status"`
+	PublishAt      *time.Time         `json:"publish_at"`
+	Version        *int               `json:"version"`      // версия, от которой редактировал клиент (или заголовок If-Match)
+	Stops          []TripStopRequest  `json:"stops"`        // если передан (в т.ч. пустой), заменяет остановки поездки
+	Language       string             `json:"language"`     // смена языка оригинала
+	VisitedFrom    *string            `json:"visited_from"` // даты поездки; пустая строка очищает
+	VisitedTo      *string            `json:"visited_to"`
 }
 
 type ReportRequest struct {
@@ -155,6 +197,85 @@ func validateSettlement(tx *gorm.DB, settlementID uint, inputName string) (strin
 	return inputName, nil
 }
 
+// resolvePostStatus проверяет запрошенный статус поста и время отложенной публикации
+func resolvePostStatus(status models.PostStatus, publishAt *time.Time) (models.PostStatus, *time.Time, error) {
+	switch status {
+	case "", models.PostStatusPublished:
+		return models.PostStatusPublished, nil, nil
+	case models.PostStatusDraft:
+		return models.PostStatusDraft, nil, nil
+	case models.PostStatusScheduled:
+		if publishAt == nil {
+			return "", nil, fmt.Errorf("publish_at is required for scheduled posts")
+		}
+		if !publishAt.After(time.Now()) {
+			return "", nil, fmt.Errorf("publish_at must be in the future")
+		}
+		return models.PostStatusScheduled, publishAt, nil
+	}
+	return "", nil, fmt.Errorf("unknown post status %q", status)
+}
+
+// loadPostTags возвращает названия тегов поста
+func loadPostTags(postID uint) []string {
+	var tags []string
+	database.DB.Table("tags").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id = ?", postID).
+		Pluck("tags.name", &tags)
+
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags
+}
+
+// broadcastNewPost рассылает NEW_POST в общий поток и поток автора
+func broadcastNewPost(post models.Post, tags []string) {
+	// Загружаем пользователя для получения имени и аватара
+	var user models.User
+	if err := database.DB.First(&user, post.UserID).Error; err != nil {
+		log.Printf("Предупреждение: не удалось загрузить данные пользователя: %v", err)
+	}
+
+	// Формируем данные для отправки
+	postData := gin.H{
+		"id":              post.ID,
+		"user_id":         post.UserID,
+		"title":           post.Title,
+		"created_at":      post.CreatedAt,
+		"settlement_name": post.SettlementName,
+		"settlement_id":   post.SettlementID,
+		"tags":            tags,
+		"photos":          feedPhotos(post.Photos),
+		"likes_count":     post.LikesCount,
+		"user_name":       user.Username,
+		"user_avatar":     user.ImageUrl,
+	}
+
+	// Создаем SSE сообщение
+	message := map[string]interface{}{
+		"type": "NEW_POST",
+		"data": postData,
+	}
+
+	data, _ := json.Marshal(message)
+
+	// Отправляем через глобальный хаб (асинхронно)
+	go func() {
+		if sse.GlobalHub != nil {
+			sse.GlobalHub.BroadcastAll <- data
+			sse.GlobalHub.BroadcastUser <- sse.UserMessage{
+				UserID: post.UserID,
+				Data:   data,
+			}
+			log.Printf("📢 SSE broadcast sent for post %d", post.ID)
+		} else {
+			log.Printf("⚠️ GlobalHub is nil, SSE not sent")
+		}
+	}()
+}
+
 func CreatePost(c *gin.Context) {
 	userID, exists := getUserIDFromContext(c)
 	if !exists {
@@ -191,12 +312,43 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
+	status, publishAt, err := resolvePostStatus(input.Status, input.PublishAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateStopReferences(len(input.Stops), input.Paragraphs, input.Photos); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := normalizeBlocks(input.Paragraphs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	visitedFrom, visitedTo, err := parseVisitDates(input.VisitedFrom, input.VisitedTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	language := supportedLanguages[0]
+	if input.Language != "" {
+		if language = normalizeLanguage(input.Language); language == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language", "supported": supportedLanguages})
+			return
+		}
+	}
+
 	// Очищаем название от лишних символов
 	input.SettlementName = utils.CleanSettlementName(input.SettlementName)
 
 	var newPost models.Post
+	var duplicateWarnings []gin.H
 
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
 		// Проверяем существование settlement
 		var settlement models.Settlement
 		if err := tx.First(&settlement, "geonameid = ?", input.SettlementID).Error; err != nil {
@@ -214,7 +366,18 @@ func CreatePost(c *gin.Context) {
 			CreatedAt:        time.Now(),
 			LikesCount:       0,
 			CommentsDisabled: false,
+			Status:           status,
+			PublishAt:        publishAt,
+			Language:         language,
+			VisitedFrom:      visitedFrom,
+			VisitedTo:        visitedTo,
+		}
+
+		slug, err := uniquePostSlug(tx, newPost.UserID, 0, utils.Slugify(newPost.Title, language))
+		if err != nil {
+			return err
 		}
+		newPost.Slug = slug
 
 		if result := tx.Create(&newPost); result.Error != nil {
 			return result.Error
@@ -238,34 +401,30 @@ func CreatePost(c *gin.Context) {
 				input.Photos[i].PostID = newPost.ID
 				input.Photos[i].IsApproved = true
 			}
+			if err := attachUploadMetadata(tx, input.Photos); err != nil {
+				return err
+			}
 			if err := tx.Create(&input.Photos).Error; err != nil {
 				return err
 			}
 		}
 
-		// Создаем теги
-		if len(input.Tags) > 0 {
-			var postTags []models.PostTag
-			for _, tagName := range input.Tags {
-				if tagName == "" {
-					continue
-				}
-
-				var tag models.Tags
-				if err := tx.Where("name = ?", tagName).FirstOrCreate(&tag, models.Tags{Name: tagName}).Error; err != nil {
-					return err
-				}
-
-				postTags = append(postTags, models.PostTag{
-					PostID: newPost.ID,
-					TagID:  tag.ID,
-				})
+		// Создаем остановки поездки
+		if len(input.Stops) > 0 {
+			stops, distanceKm, err := buildTripStops(tx, input.Stops)
+			if err != nil {
+				return err
 			}
+			if err := replaceTripStops(tx, newPost.ID, stops, distanceKm); err != nil {
+				return err
+			}
+			newPost.RouteDistanceKm = distanceKm
+		}
 
-			if len(postTags) > 0 {
-				if err := tx.Create(&postTags).Error; err != nil {
-					return err
-				}
+		// Создаем теги (нормализованные, синонимы заменяются основными тегами)
+		if len(input.Tags) > 0 {
+			if err := replacePostTags(tx, newPost.ID, input.Tags); err != nil {
+				return err
 			}
 		}
 
@@ -315,11 +474,42 @@ func CreatePost(c *gin.Context) {
 			}
 		}
 
+		// Блоки с фото должны ссылаться на фото этого поста
+		if err := validatePhotoBlocks(tx, newPost.ID); err != nil {
+			return err
+		}
+
+		// Отрывок и время чтения для ленты
+		if err := updatePostPreview(tx, newPost.ID); err != nil {
+			return err
+		}
+		if err := updatePostSearchDocument(tx, newPost.ID); err != nil {
+			return err
+		}
+
+		// Почти одинаковые посты: свои - предупреждение автору, чужие - отметка модераторам
+		duplicates, err := checkPostDuplicates(tx, newPost)
+		if err != nil {
+			return err
+		}
+		duplicateWarnings = duplicates
+
+		// Первая версия в истории правок
+		if _, err := createPostRevision(tx, newPost.ID, int(userID), nil); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
 	if err != nil {
 		log.Printf("❌ Ошибка создания поста: %v", err)
+		var blockErr *blockError
+		var stopErr *tripStopError
+		if errors.As(err, &blockErr) || errors.As(err, &stopErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create post",
 			"details": err.Error(),
@@ -327,53 +517,23 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
-	// Загружаем пользователя для получения имени и аватара
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		log.Printf("Предупреждение: не удалось загрузить данные пользователя: %v", err)
-	}
-
-	// Формируем данные для отправки
-	postData := gin.H{
-		"id":              newPost.ID,
-		"user_id":         newPost.UserID,
-		"title":           newPost.Title,
-		"created_at":      newPost.CreatedAt,
-		"settlement_name": newPost.SettlementName,
-		"settlement_id":   newPost.SettlementID,
-		"tags":            input.Tags,
-		"photos":          newPost.Photos,
-		"likes_count":     0,
-		"user_name":       user.Username,
-		"user_avatar":     user.ImageUrl,
-	}
-
-	// Создаем SSE сообщение
-	message := map[string]interface{}{
-		"type": "NEW_POST",
-		"data": postData,
+	// Черновики и отложенные посты не попадают в ленту до публикации
+	if newPost.Status == models.PostStatusPublished {
+		broadcastNewPost(newPost, loadPostTags(newPost.ID))
+		notifySavedSearches(newPost)
 	}
 
-	data, _ := json.Marshal(message)
-
-	// Отправляем через глобальный хаб (асинхронно)
-	go func() {
-		if sse.GlobalHub != nil {
-			sse.GlobalHub.BroadcastAll <- data
-			sse.GlobalHub.BroadcastUser <- sse.UserMessage{
-				UserID: int(userID),
-				Data:   data,
-			}
-			log.Printf("📢 SSE broadcast sent for post %d", newPost.ID)
-		} else {
-			log.Printf("⚠️ GlobalHub is nil, SSE not sent")
-		}
-	}()
+	// Предупреждаем, если фото сняты далеко от выбранного населённого пункта (часто выбран не тот тёзка)
+	warnings := checkPhotoLocations(database.DB, int(userID), input.SettlementID, input.Stops, input.Photos)
+	warnings = append(warnings, duplicateWarnings...)
 
 	log.Printf("✅ Post creation completed successfully for post ID: %d", newPost.ID)
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Post created successfully",
-		"id":      newPost.ID,
+		"message":    "Post created successfully",
+		"id":         newPost.ID,
+		"status":     newPost.Status,
+		"publish_at": newPost.PublishAt,
+		"warnings":   warnings,
 	})
 }
 
@@ -469,13 +629,29 @@ func GetUserPosts(c *gin.Context) {
 
 	fmt.Printf("GetUserPosts DEBUG: Fetching posts for **UserID: %d**\n", userID)
 
+	// Черновики и отложенные посты отдаются отдельно: ?status=draft или ?status=scheduled
+	status := models.PostStatus(c.DefaultQuery("status", string(models.PostStatusPublished)))
+	switch status {
+	case models.PostStatusDraft, models.PostStatusScheduled, models.PostStatusPublished:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if page.Cursor != nil && page.Cursor.Sort != "new" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
 	var posts []models.Post
 	result := database.DB.
-		Where("user_id = ? AND is_approved = ?", userID, true). // Добавлен фильтр is_approved
-		Preload("User").
-		Preload("Photos").
-		Preload("Paragraphs").
-		Preload("Settlement").
+		Where("user_id = ? AND is_approved = ? AND status = ?", userID, true, status). // Добавлен фильтр is_approved
+		Scopes(newestFirst(page)).
 		Find(&posts)
 
 	if result.Error != nil {
@@ -483,42 +659,17 @@ func GetUserPosts(c *gin.Context) {
 		return
 	}
 
-	response := make([]PostResponse, 0)
-	for _, p := range posts {
-		var tags []string
-		database.DB.Table("tags").
-			Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
-			Where("post_tags.post_id = ?", p.ID).
-			Pluck("tags.name", &tags)
-
-		if tags == nil {
-			tags = []string{}
-		}
-
-		userAvatar := ""
-		userName := "Неизвестный пользователь"
-		if p.User.ID != 0 {
-			userAvatar = p.User.ImageUrl
-			userName = p.User.Username
-		}
-
-		respItem := PostResponse{
-			ID:             p.ID,
-			UserID:         uint(p.UserID),
-			Title:          p.Title,
-			Date:           p.CreatedAt,
-			SettlementName: p.SettlementName,
-			SettlementID:   p.SettlementID,
-			Tags:           tags,
-			Photos:         p.Photos,
-			LikesCount:     p.LikesCount,
-			UserAvatar:     userAvatar,
-			UserName:       userName,
-		}
-		response = append(response, respItem)
+	posts, hasMore := utils.TrimPage(posts, page.Limit)
+	response, err := feedPostResponses(c, posts, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user posts"})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       response,
+		"next_cursor": newestFirstCursor(posts, hasMore),
+	})
 }
 
 func GetPost(c *gin.Context) {
@@ -530,6 +681,11 @@ func GetPost(c *gin.Context) {
 		return
 	}
 
+	respondPostDetail(c, uint(postID))
+}
+
+// respondPostDetail отдаёт пост целиком; используется при обращении по ID и по slug
+func respondPostDetail(c *gin.Context, postID uint) {
 	var post models.Post
 
 	result := database.DB.Where("id = ?", postID).
@@ -541,6 +697,10 @@ func GetPost(c *gin.Context) {
 		Preload("Photos", func(db *gorm.DB) *gorm.DB {
 			return db.Order("\"order\" ASC")
 		}).
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("trip_stops.order ASC")
+		}).
+		Preload("Stops.Settlement").
 		First(&post)
 
 	if result.Error != nil {
@@ -554,12 +714,27 @@ func GetPost(c *gin.Context) {
 		return
 	}
 
+	// Неопубликованные посты видят только автор и соавторы
+	if post.Status != models.PostStatusPublished {
+		viewerID, _ := getUserIDFromContext(c)
+		if !isPostMember(database.DB, post, int(viewerID)) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return
+		}
+	}
+
 	var tags []string
 	database.DB.Table("tags").
 		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
 		Where("post_tags.post_id = ?", post.ID).
 		Pluck("tags.name", &tags)
 
+	if post.Stops == nil {
+		post.Stops = []models.TripStop{}
+	}
+
+	language, availableLanguages, translatedAt := localizePost(database.DB, c, &post)
+
 	response := DetailPostResponse{
 		ID:               post.ID,
 		UserID:           uint(post.UserID),
@@ -568,121 +743,151 @@ func GetPost(c *gin.Context) {
 		SettlementName:   post.SettlementName,
 		SettlementID:     post.SettlementID,
 		Tags:             tags,
-		PreviewText:      "",
+		PreviewText:      post.PreviewText,
+		WordCount:        post.WordCount,
+		ReadingTime:      post.ReadingTimeMinutes,
 		Paragraphs:       post.Paragraphs,
 		Photos:           post.Photos,
 		LikesCount:       post.LikesCount,
 		CommentsDisabled: post.CommentsDisabled,
+		Status:           post.Status,
+		PublishAt:        post.PublishAt,
+		Version:          post.Version,
+		Stops:            post.Stops,
+		RouteDistanceKm:  post.RouteDistanceKm,
+		Slug:             post.Slug,
+		VisitedFrom:      post.VisitedFrom,
+		VisitedTo:        post.VisitedTo,
+
+		Language:           language,
+		OriginalLanguage:   postLanguage(post),
+		AvailableLanguages: availableLanguages,
 	}
 
+	// Просмотр записывается агрегатором в фоне, ответ его не ждёт
+	recordView(c, post)
+
+	c.Header("ETag", localizedPostETag(post.Version, language, len(availableLanguages), translatedAt))
 	c.JSON(http.StatusOK, response)
 }
 
+// GetPublicFeed - лента опубликованных постов: sort (new, popular, trending), search,
+// фильтры места, автора, фото, лайков и тегов (см. parseFeedFilter), даты поездки, cursor, limit.
+// Для trending ответ содержит score_epoch - эпоху оценок актуальности; курсор действует только в своей эпохе,
+// после пересчёта оценок он отклоняется с 409, и ленту нужно загрузить с первой страницы.
 func GetPublicFeed(c *gin.Context) {
 	var posts []models.Post
 
-	db := database.DB.Model(&models.Post{}).Where("is_approved = ?", true)
+	db := database.DB.Model(&models.Post{}).
+		Where("is_approved = ?", true).
+		Where("posts.status = ?", models.PostStatusPublished)
 
 	searchQuery := c.Query("search")
 	if searchQuery != "" {
 		searchTerm := "%" + searchQuery + "%"
-		// Ищем по названию поста или названию населенного пункта
+		// Ищем по названию поста на любом языке или названию населенного пункта
 		db = db.Where(
 			database.DB.Where("posts.title ILIKE ?", searchTerm).
-				Or("posts.settlement_name ILIKE ?", searchTerm),
+				Or("posts.settlement_name ILIKE ?", searchTerm).
+				Or("EXISTS (SELECT 1 FROM post_translations WHERE post_translations.post_id = posts.id AND post_translations.title ILIKE ?)", searchTerm),
 		)
 	}
 
-	tagsQuery := c.Query("tags")
-	if tagsQuery != "" {
-		tagSearchTerm := "%" + tagsQuery + "%"
+	// Место, автор, фото, лайки и теги
+	filterScope, err := feedFilterScope(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		var postIDsWithTags []uint
-		database.DB.Table("post_tags").
-			Select("post_id").
-			Joins("JOIN tags ON tags.id = post_tags.tag_id").
-			Where("tags.name ILIKE ?", tagSearchTerm).
-			Group("post_id").
-			Pluck("post_id", &postIDsWithTags)
+	// Даты поездки: диапазон, месяц или сезон, год
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	db = db.Scopes(filterScope, visitFilter.Scope)
 
-		if len(postIDsWithTags) > 0 {
-			db = db.Where("posts.id IN (?)", postIDsWithTags)
-		} else {
-			db = db.Where("1 = 0")
-		}
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// НОВЫЙ КОД: Сортировка
+	// Сортировка; created_at и id в конце ключа дают устойчивый порядок для курсора
 	sortBy := c.Query("sort")
+	if sortBy != "popular" && sortBy != "trending" {
+		sortBy = "new"
+	}
+	if page.Cursor != nil && page.Cursor.Sort != sortBy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
+	scoreEpoch := currentHotScoreEpoch()
+
 	switch sortBy {
 	case "popular":
-		db = db.Order("likes_count DESC, created_at DESC")
+		db = db.Order("posts.likes_count DESC, posts.created_at DESC, posts.id DESC")
+		if page.Cursor != nil {
+			db = db.Where(utils.KeysetSQL("posts.likes_count", "posts.created_at", "posts.id"),
+				page.Cursor.Score, page.Cursor.Time, page.Cursor.ID)
+		}
 	case "trending":
-		// Актуальные: лайки за последние 24 часа
-		db = db.Joins(`
-            LEFT JOIN (
-                SELECT post_id, COUNT(*) as recent_likes 
-                FROM likes 
-                WHERE created_at > NOW() - INTERVAL '24 hours'
-                GROUP BY post_id
-            ) recent ON recent.post_id = posts.id
-        `).Order("COALESCE(recent.recent_likes, 0) DESC, posts.created_at DESC")
+		// Актуальные: оценка с затуханием по времени, её пересчитывает RunHotScoreUpdater.
+		// Эпоха читается до запроса: страница считана из неё или более новой, и курсор не пропустит пересчёт.
+		if page.Cursor != nil && (page.Cursor.AsOf == nil || !page.Cursor.AsOf.Equal(scoreEpoch)) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       "Trending order has changed, reload the feed",
+				"score_epoch": scoreEpoch,
+			})
+			return
+		}
+		db = db.Order("posts.hot_score DESC, posts.created_at DESC, posts.id DESC")
+		if page.Cursor != nil {
+			db = db.Where(utils.KeysetSQL("posts.hot_score", "posts.created_at", "posts.id"),
+				page.Cursor.Score, page.Cursor.Time, page.Cursor.ID)
+		}
 	default: // "new" или любой другой
-		db = db.Order("created_at DESC")
+		db = db.Scopes(newestFirst(page))
 	}
 
-	result := db.
-		Preload("User").
-		Preload("Settlement").
-		Preload("Paragraphs", func(db *gorm.DB) *gorm.DB {
-			return db.Order("paragraphs.order ASC")
-		}).
-		Preload("Photos").
-		Order("created_at desc").
-		Find(&posts)
+	result := db.Limit(page.Limit + 1).Find(&posts)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch public feed", "details": result.Error.Error()})
 		return
 	}
 
-	var response []PostResponse
-	for _, p := range posts {
-		var tags []string
-		database.DB.Table("tags").
-			Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
-			Where("post_tags.post_id = ?", p.ID).
-			Pluck("tags.name", &tags)
-
-		if tags == nil {
-			tags = []string{}
-		}
-
-		userAvatar := ""
-		userName := "Неизвестный пользователь"
-
-		if p.User.ID != 0 {
-			userAvatar = p.User.ImageUrl
-			userName = p.User.Username
+	posts, hasMore := utils.TrimPage(posts, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := posts[len(posts)-1]
+		cursor := utils.Cursor{Sort: sortBy, Time: last.CreatedAt, ID: last.ID}
+		switch sortBy {
+		case "popular":
+			cursor.Score = int64(last.LikesCount)
+		case "trending":
+			cursor.Score = last.HotScore
+			cursor.AsOf = &scoreEpoch
 		}
+		nextCursor = utils.StringPtr(utils.EncodeCursor(cursor))
+	}
 
-		respItem := PostResponse{
-			ID:             p.ID,
-			UserID:         uint(p.UserID),
-			Title:          p.Title,
-			Date:           p.CreatedAt,
-			SettlementName: p.SettlementName,
-			SettlementID:   p.SettlementID,
-			Tags:           tags,
-			Photos:         p.Photos,
-			LikesCount:     p.LikesCount,
-			UserAvatar:     userAvatar,
-			UserName:       userName,
-		}
-		response = append(response, respItem)
+	response, err := feedPostResponses(c, posts, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch public feed"})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	body := gin.H{
+		"posts":       response,
+		"next_cursor": nextCursor,
+	}
+	if sortBy == "trending" {
+		body["score_epoch"] = scoreEpoch
+	}
+	c.JSON(http.StatusOK, body)
 }
 
 func UpdatePost(c *gin.Context) {
@@ -706,12 +911,84 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
+	var newStatus models.PostStatus
+	var newPublishAt *time.Time
+	if input.Status != nil {
+		newStatus, newPublishAt, err = resolvePostStatus(*input.Status, input.PublishAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := normalizeBlocks(input.Paragraphs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Теги сравниваются с текущими при проверке конфликта версий - приводим к каноничному виду заранее
+	if len(input.Tags) > 0 {
+		input.Tags = normalizeTagNames(database.DB, input.Tags)
+	}
+
+	newLanguage := ""
+	if input.Language != "" {
+		if newLanguage = normalizeLanguage(input.Language); newLanguage == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language", "supported": supportedLanguages})
+			return
+		}
+	}
+
+	expectedVersion, hasExpectedVersion, err := expectedPostVersion(c, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var updatedPost models.Post
+	var warnings []gin.H
+	publishedNow := false
+
 	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку поста, чтобы параллельные сохранения выполнялись по очереди
 		var post models.Post
-		if err := tx.First(&post, "id = ? AND user_id = ?", postID, userID).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, "id = ?", postID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errPostNotFound
+			}
+			return err
+		}
+
+		// Редактировать могут автор и соавторы с ролью editor
+		if !canEditPost(tx, post, int(userID)) {
+			return errEditForbidden
+		}
+
+		// Смена статуса: публикация черновика, перенос или отмена отложенной публикации
+		if input.Status != nil {
+			if post.UserID != int(userID) {
+				return errStatusForbidden
+			}
+			if newStatus == models.PostStatusPublished && post.Status != models.PostStatusPublished {
+				// Дата поста — момент, когда он появился в ленте
+				post.CreatedAt = time.Now()
+				publishedNow = true
+			}
+			post.Status = newStatus
+			post.PublishAt = newPublishAt
+		}
+
+		// Для постов, созданных до появления истории, сохраняем исходное состояние
+		if err := ensureInitialRevision(tx, post); err != nil {
 			return err
 		}
 
+		if hasExpectedVersion {
+			if conflict := detectVersionConflict(tx, post, expectedVersion, input); conflict != nil {
+				return conflict
+			}
+		}
+
 		// Если меняется settlement, проверяем его существование и корректируем название
 		if input.SettlementID != 0 {
 			correctedName, err := validateSettlement(tx, input.SettlementID, input.SettlementName)
@@ -726,61 +1003,201 @@ func UpdatePost(c *gin.Context) {
 			post.Title = input.Title
 		}
 
+		// Даты поездки: переданное поле заменяет текущее, пустая строка очищает
+		if input.VisitedFrom != nil || input.VisitedTo != nil {
+			from, to := formatDate(post.VisitedFrom), formatDate(post.VisitedTo)
+			if input.VisitedFrom != nil {
+				from = *input.VisitedFrom
+			}
+			if input.VisitedTo != nil {
+				to = *input.VisitedTo
+			}
+			visitedFrom, visitedTo, err := parseVisitDates(from, to)
+			if err != nil {
+				return &visitDateError{err}
+			}
+			post.VisitedFrom = visitedFrom
+			post.VisitedTo = visitedTo
+		}
+
+		// Язык оригинала нельзя сменить на язык, для которого уже есть перевод
+		if newLanguage != "" && newLanguage != postLanguage(post) {
+			var translations int64
+			if err := tx.Model(&models.PostTranslation{}).
+				Where("post_id = ? AND language = ?", post.ID, newLanguage).
+				Count(&translations).Error; err != nil {
+				return err
+			}
+			if translations > 0 {
+				return errLanguageHasTranslation
+			}
+			post.Language = newLanguage
+		}
+
 		if err := tx.Save(&post).Error; err != nil {
 			return err
 		}
 
+		// Новый заголовок - новый адрес; старый продолжит работать через редирект
+		if input.Title != "" || newLanguage != "" {
+			if err := updatePostSlug(tx, &post); err != nil {
+				return err
+			}
+		}
+
 		// Обновляем параграфы
 		if len(input.Paragraphs) > 0 {
-			tx.Where("post_id = ?", post.ID).Delete(&models.Paragraph{})
-			for i := range input.Paragraphs {
-				input.Paragraphs[i].PostID = post.ID
-				input.Paragraphs[i].ID = 0
-			}
-			if err := tx.Create(&input.Paragraphs).Error; err != nil {
+			if err := replacePostParagraphs(tx, post.ID, input.Paragraphs); err != nil {
 				return err
 			}
 		}
 
 		// Обновляем фото
 		if len(input.Photos) > 0 {
-			tx.Where("post_id = ?", post.ID).Delete(&models.PostPhoto{})
-			for i := range input.Photos {
-				input.Photos[i].PostID = post.ID
-				input.Photos[i].ID = 0
-				input.Photos[i].IsApproved = true
-			}
-			if err := tx.Create(&input.Photos).Error; err != nil {
+			if err := replacePostPhotos(tx, post.ID, input.Photos); err != nil {
 				return err
 			}
 		}
 
 		// Обновляем теги
 		if len(input.Tags) > 0 {
-			tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{})
-			for _, tagName := range input.Tags {
-				if tagName == "" {
-					continue
-				}
-				var tag models.Tags
-				if err := tx.Where("name = ?", tagName).FirstOrCreate(&tag, models.Tags{Name: tagName}).Error; err != nil {
-					return err
-				}
-				if err := tx.Create(&models.PostTag{PostID: post.ID, TagID: tag.ID}).Error; err != nil {
-					return err
-				}
+			if err := replacePostTags(tx, post.ID, input.Tags); err != nil {
+				return err
+			}
+		}
+
+		// Обновляем остановки поездки
+		stopCount := len(input.Stops)
+		if input.Stops != nil {
+			stops, distanceKm, err := buildTripStops(tx, input.Stops)
+			if err != nil {
+				return err
+			}
+			if err := replaceTripStops(tx, post.ID, stops, distanceKm); err != nil {
+				return err
 			}
+		} else {
+			var existing int64
+			if err := tx.Model(&models.TripStop{}).Where("post_id = ?", post.ID).Count(&existing).Error; err != nil {
+				return err
+			}
+			stopCount = int(existing)
+		}
+		if err := validateStopReferences(stopCount, input.Paragraphs, input.Photos); err != nil {
+			return err
+		}
+		if err := validatePhotoBlocks(tx, post.ID); err != nil {
+			return err
+		}
+		if err := updatePostPreview(tx, post.ID); err != nil {
+			return err
+		}
+		if err := updatePostSearchDocument(tx, post.ID); err != nil {
+			return err
+		}
+		duplicates, err := checkPostDuplicates(tx, post)
+		if err != nil {
+			return err
+		}
+		warnings = duplicates
+
+		// Сохраняем снимок новой версии поста, номер версии поста совпадает с номером ревизии
+		revision, err := createPostRevision(tx, post.ID, int(userID), nil)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&post).Update("version", revision.Version).Error; err != nil {
+			return err
 		}
 
+		updatedPost = post
 		return nil
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed", "details": err.Error()})
+		var conflict *versionConflictError
+		var blockErr *blockError
+		var visitErr *visitDateError
+		var stopErr *tripStopError
+		switch {
+		case errors.As(err, &conflict):
+			c.JSON(http.StatusConflict, conflict.response())
+		case errors.As(err, &blockErr), errors.As(err, &visitErr), errors.As(err, &stopErr), errors.Is(err, errLanguageHasTranslation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, errPostNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		case errors.Is(err, errEditForbidden), errors.Is(err, errStatusForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed", "details": err.Error()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Post updated successfully"})
+	if publishedNow {
+		database.DB.Where("post_id = ?", updatedPost.ID).Order("\"order\" ASC").Find(&updatedPost.Photos)
+		broadcastNewPost(updatedPost, loadPostTags(updatedPost.ID))
+		notifySavedSearches(updatedPost)
+	}
+
+	c.Header("ETag", postETag(updatedPost.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Post updated successfully",
+		"version":  updatedPost.Version,
+		"slug":     updatedPost.Slug,
+		"warnings": warnings,
+	})
+}
+
+// replacePostParagraphs заменяет все параграфы поста на переданные
+func replacePostParagraphs(tx *gorm.DB, postID uint, paragraphs []models.Paragraph) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&models.Paragraph{}).Error; err != nil {
+		return err
+	}
+	if len(paragraphs) == 0 {
+		return nil
+	}
+	for i := range paragraphs {
+		paragraphs[i].PostID = postID
+		paragraphs[i].ID = 0
+	}
+	return tx.Create(&paragraphs).Error
+}
+
+// replacePostPhotos заменяет все фото поста на переданные
+func replacePostPhotos(tx *gorm.DB, postID uint, photos []models.PostPhoto) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&models.PostPhoto{}).Error; err != nil {
+		return err
+	}
+	if len(photos) == 0 {
+		return nil
+	}
+	for i := range photos {
+		photos[i].PostID = postID
+		photos[i].ID = 0
+		photos[i].IsApproved = true
+	}
+	if err := attachUploadMetadata(tx, photos); err != nil {
+		return err
+	}
+	return tx.Create(&photos).Error
+}
+
+// replacePostTags заменяет все теги поста на переданные
+func replacePostTags(tx *gorm.DB, postID uint, tagNames []string) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&models.PostTag{}).Error; err != nil {
+		return err
+	}
+	tags, err := resolveTags(tx, tagNames)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := tx.Create(&models.PostTag{PostID: postID, TagID: tag.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func DeletePost(c *gin.Context) {
@@ -808,59 +1225,8 @@ func DeletePost(c *gin.Context) {
 			return result.Error
 		}
 
-		fmt.Printf("DEBUG: Found post ID: %d, SettlementID: %d\n", post.ID, post.SettlementID)
-
-		// Удаляем связанные данные
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Like{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Favourite{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Complaint{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Paragraph{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostPhoto{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{}).Error; err != nil {
-			return err
-		}
-
-		// ========== НОВЫЙ КОД ДЛЯ КОЛЛАБОРАЦИЙ ==========
-		// Удаляем всех соавторов поста
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostCollaborator{}).Error; err != nil {
-			log.Printf("Ошибка при удалении соавторов: %v", err)
-			return err
-		}
-
-		// Удаляем все приглашения, связанные с постом
-		if err := tx.Where("post_id = ?", post.ID).Delete(&models.CollaborationInvite{}).Error; err != nil {
-			log.Printf("Ошибка при удалении приглашений: %v", err)
-			return err
-		}
-		// ==============================================
-
-		// Удаляем пост
-		fmt.Printf("DEBUG: Deleting post ID: %d\n", post.ID)
-		if err := tx.Delete(&post).Error; err != nil {
-			fmt.Printf("DEBUG: Error deleting post: %v\n", err)
-			return err
-		}
-
-		return nil
+		// Пост уходит в корзину: скрыт отовсюду, связанные данные удалит очистка корзины
+		return tx.Delete(&post).Error
 	})
 
 	if err != nil {
@@ -887,7 +1253,10 @@ func DeletePost(c *gin.Context) {
 		}
 	}()
 
-	c.JSON(http.StatusOK, gin.H{"message": "Post and associated data deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Post moved to trash",
+		"purge_at": time.Now().Add(trashRetention()),
+	})
 }
 
 func ReportPost(c *gin.Context) {
@@ -975,13 +1344,20 @@ func GetUserPostsByID(c *gin.Context) {
 
 	fmt.Printf("GetUserPostsByID DEBUG: Fetching posts for user ID: %d\n", userID)
 
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if page.Cursor != nil && page.Cursor.Sort != "new" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
 	var posts []models.Post
 	result := database.DB.
-		Where("user_id = ? AND is_approved = ?", userID, true). // Добавлен фильтр is_approved
-		Preload("User").
-		Preload("Photos").
-		Preload("Paragraphs").
-		Preload("Settlement").
+		Where("user_id = ? AND is_approved = ? AND status = ?", userID, true, models.PostStatusPublished). // Добавлен фильтр is_approved
+		Scopes(newestFirst(page)).
 		Find(&posts)
 
 	if result.Error != nil {
@@ -989,42 +1365,17 @@ func GetUserPostsByID(c *gin.Context) {
 		return
 	}
 
-	response := make([]PostResponse, 0)
-	for _, p := range posts {
-		var tags []string
-		database.DB.Table("tags").
-			Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
-			Where("post_tags.post_id = ?", p.ID).
-			Pluck("tags.name", &tags)
-
-		if tags == nil {
-			tags = []string{}
-		}
-
-		userAvatar := ""
-		userName := "Неизвестный пользователь"
-		if p.User.ID != 0 {
-			userAvatar = p.User.ImageUrl
-			userName = p.User.Username
-		}
-
-		respItem := PostResponse{
-			ID:             p.ID,
-			UserID:         uint(p.UserID),
-			Title:          p.Title,
-			Date:           p.CreatedAt,
-			SettlementName: p.SettlementName,
-			SettlementID:   p.SettlementID,
-			Tags:           tags,
-			Photos:         p.Photos,
-			LikesCount:     p.LikesCount,
-			UserAvatar:     userAvatar,
-			UserName:       userName,
-		}
-		response = append(response, respItem)
+	posts, hasMore := utils.TrimPage(posts, page.Limit)
+	response, err := feedPostResponses(c, posts, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user posts"})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       response,
+		"next_cursor": newestFirstCursor(posts, hasMore),
+	})
 }
 
 func ToggleComments(c *gin.Context) {
@@ -0,0 +1,122 @@
+package post
+
+import (
+	"log"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Длина отрывка для карточек ленты, символов
+const previewLength = 280
+
+// postTextStats - отрывок, число слов и время чтения по блокам поста.
+// Отрывок собирается из текстовых блоков, а если их нет - из цитат и советов; заголовки и подписи только считаются.
+func postTextStats(paragraphs []models.Paragraph, photos int) (string, int, int) {
+	var body, fallback []string
+	words := 0
+
+	for _, p := range paragraphs {
+		if p.Type == models.BlockMapPin {
+			continue
+		}
+		text := utils.MarkdownToPlain(p.Content)
+		if text == "" {
+			continue
+		}
+		words += utils.CountWords(text)
+
+		switch p.Type {
+		case models.BlockText, "":
+			body = append(body, text)
+		case models.BlockQuote, models.BlockTip:
+			fallback = append(fallback, text)
+		}
+	}
+
+	if len(body) == 0 {
+		body = fallback
+	}
+	preview := utils.Excerpt(strings.Join(body, " "), previewLength)
+
+	return preview, words, utils.ReadingMinutes(words, photos)
+}
+
+// countPostPhotos - число одобренных фото поста (учитываются во времени чтения)
+func countPostPhotos(tx *gorm.DB, postID uint) (int, error) {
+	var photos int64
+	err := tx.Model(&models.PostPhoto{}).Where("post_id = ? AND is_approved = ?", postID, true).Count(&photos).Error
+	return int(photos), err
+}
+
+// updatePostPreview пересчитывает отрывок, число слов и время чтения поста после сохранения
+func updatePostPreview(tx *gorm.DB, postID uint) error {
+	var paragraphs []models.Paragraph
+	if err := tx.Where("post_id = ?", postID).Order("paragraphs.order ASC").Find(&paragraphs).Error; err != nil {
+		return err
+	}
+	photos, err := countPostPhotos(tx, postID)
+	if err != nil {
+		return err
+	}
+
+	preview, words, minutes := postTextStats(paragraphs, photos)
+	return tx.Unscoped().Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{
+		"preview_text":         preview,
+		"word_count":           words,
+		"reading_time_minutes": minutes,
+	}).Error
+}
+
+// setTranslationPreview заполняет отрывок, число слов и время чтения перевода
+func setTranslationPreview(tx *gorm.DB, translation *models.PostTranslation, paragraphs []models.Paragraph) error {
+	photos, err := countPostPhotos(tx, translation.PostID)
+	if err != nil {
+		return err
+	}
+	translation.PreviewText, translation.WordCount, translation.ReadingTimeMinutes = postTextStats(paragraphs, photos)
+	return nil
+}
+
+// BackfillPostPreviews заполняет отрывки у постов и переводов, сохранённых до их появления.
+// Время чтения всегда не меньше минуты, поэтому ноль означает, что пост ещё не обработан.
+func BackfillPostPreviews() {
+	var postIDs []uint
+	if err := database.DB.Unscoped().Model(&models.Post{}).
+		Where("reading_time_minutes = 0").
+		Order("id ASC").
+		Pluck("id", &postIDs).Error; err != nil {
+		log.Printf("Ошибка выборки постов без отрывка: %v", err)
+		return
+	}
+	for _, id := range postIDs {
+		if err := updatePostPreview(database.DB, id); err != nil {
+			log.Printf("Не удалось посчитать отрывок поста %d: %v", id, err)
+		}
+	}
+
+	var translations []models.PostTranslation
+	if err := database.DB.Where("reading_time_minutes = 0").Find(&translations).Error; err != nil {
+		log.Printf("Ошибка выборки переводов без отрывка: %v", err)
+		return
+	}
+	for i := range translations {
+		t := &translations[i]
+		if err := setTranslationPreview(database.DB, t, decodeTranslationParagraphs(*t)); err != nil {
+			log.Printf("Не удалось посчитать отрывок перевода %d: %v", t.ID, err)
+			continue
+		}
+		database.DB.Model(t).Updates(map[string]interface{}{
+			"preview_text":         t.PreviewText,
+			"word_count":           t.WordCount,
+			"reading_time_minutes": t.ReadingTimeMinutes,
+		})
+	}
+
+	if len(postIDs) > 0 || len(translations) > 0 {
+		log.Printf("📝 Посчитаны отрывки для %d постов и %d переводов", len(postIDs), len(translations))
+	}
+}
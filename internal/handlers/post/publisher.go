@@ -0,0 +1,60 @@
+package post
+
+import (
+	"log"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RunScheduledPublisher периодически публикует отложенные посты, у которых наступило время publish_at.
+// NEW_POST рассылается только в момент публикации, а не при создании поста.
+func RunScheduledPublisher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		publishDuePosts()
+	}
+}
+
+func publishDuePosts() {
+	var due []models.Post
+	err := database.DB.
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, time.Now()).
+		Find(&due).Error
+	if err != nil {
+		log.Printf("Ошибка выборки отложенных постов: %v", err)
+		return
+	}
+
+	for _, post := range due {
+		now := time.Now()
+
+		// Условие по статусу защищает от повторной публикации, если автор успел изменить пост
+		result := database.DB.Model(&models.Post{}).
+			Where("id = ? AND status = ?", post.ID, models.PostStatusScheduled).
+			Updates(map[string]interface{}{
+				"status":     models.PostStatusPublished,
+				"created_at": now,
+			})
+		if result.Error != nil {
+			log.Printf("Ошибка публикации поста %d: %v", post.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		post.Status = models.PostStatusPublished
+		post.CreatedAt = now
+		broadcastNewPost(post, loadPostTags(post.ID))
+		notifySavedSearches(post)
+		log.Printf("✅ Отложенный пост %d опубликован", post.ID)
+	}
+}
@@ -3,11 +3,13 @@ package post
 import (
 	"net/http"
 	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
 	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // PostRecommendationResponse - структура для ответа с рекомендациями
@@ -18,16 +20,22 @@ type PostRecommendationResponse struct {
 	SettlementName string          `json:"settlement_name"`
 	SettlementID   uint            `json:"settlement_id"`
 	Tags           []string        `json:"tags"`
-	Photos         []PhotoResponse `json:"photos"`
+	Photos         []PhotoResponse `json:"photos"` // только обложка
+	PhotosCount    int             `json:"photos_count"`
 	LikesCount     int             `json:"likes_count"`
+	CommentsCount  int             `json:"comments_count"`
 	UserID         uint            `json:"user_id"`
 	UserAvatar     string          `json:"user_avatar"`
 	UserName       string          `json:"user_name"`
+	VisitedFrom    *time.Time      `json:"visited_from,omitempty"`
+	VisitedTo      *time.Time      `json:"visited_to,omitempty"`
 }
 
 // PhotoResponse - структура для фото в ответе
 type PhotoResponse struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
 }
 
 // GetGeoRecommendations - гео-рекомендации (места, похожие на те, что пользователь уже лайкал)
@@ -45,6 +53,13 @@ func GetGeoRecommendations(c *gin.Context) {
 		}
 	}
 
+	// Фильтр по датам поездки: "зимние поездки", поездки за год и т.п.
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var posts []models.Post
 
 	// 1. Какие локации пользователь уже "любит" (лайки/избранное)
@@ -65,34 +80,24 @@ func GetGeoRecommendations(c *gin.Context) {
 	allSettlements := append(likedSettlements, favouritedSettlements...)
 
 	if len(allSettlements) == 0 {
-		// Если нет истории, показываем популярные посты (исключая свои)
-		database.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id, username, image_url")
-		}).
-			Preload("Settlement").
-			Preload("Photos", func(db *gorm.DB) *gorm.DB {
-				return db.Where("is_approved = true").Order("\"order\" ASC")
-			}).
-			Preload("Tags").
+		// Если нет истории, показываем актуальные посты (исключая свои)
+		database.DB.Preload("Settlement").
+			Scopes(visitFilter.Scope).
 			Where("is_approved = true").
+			Where("status = ?", models.PostStatusPublished).
 			Where("user_id != ?", userID).
 			Where("id NOT IN (?)",
 				database.DB.Table("posts").Select("id").Where("user_id = ?", userID),
 			).
-			Order("likes_count DESC").
+			Order("hot_score DESC, likes_count DESC").
 			Limit(limit).
 			Find(&posts)
 	} else {
 		// 2. Ищем посты из этих локаций, которые пользователь еще не видел
-		err := database.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id, username, image_url")
-		}).
-			Preload("Settlement").
-			Preload("Photos", func(db *gorm.DB) *gorm.DB {
-				return db.Where("is_approved = true").Order("\"order\" ASC")
-			}).
-			Preload("Tags").
+		err := database.DB.Preload("Settlement").
+			Scopes(visitFilter.Scope).
 			Where("is_approved = true").
+			Where("status = ?", models.PostStatusPublished).
 			Where("settlement_id IN (?)", allSettlements).
 			Where("user_id != ?", userID).
 			Where("id NOT IN (?)",
@@ -115,7 +120,11 @@ func GetGeoRecommendations(c *gin.Context) {
 	}
 
 	// Форматируем ответ
-	response := formatRecommendationResponse(posts)
+	response, err := formatRecommendationResponse(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"posts": response, "type": "geo"})
 }
 
@@ -134,6 +143,13 @@ func GetFollowRecommendations(c *gin.Context) {
 		}
 	}
 
+	// Фильтр по датам поездки: "зимние поездки", поездки за год и т.п.
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var posts []models.Post
 
 	// Сначала проверяем, есть ли у пользователя подписки
@@ -143,36 +159,26 @@ func GetFollowRecommendations(c *gin.Context) {
 		Count(&followCount)
 
 	if followCount == 0 {
-		// Если нет подписок, показываем популярные посты (исключая свои)
-		database.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id, username, image_url")
-		}).
-			Preload("Settlement").
-			Preload("Photos", func(db *gorm.DB) *gorm.DB {
-				return db.Where("is_approved = true").Order("\"order\" ASC")
-			}).
-			Preload("Tags").
+		// Если нет подписок, показываем актуальные посты (исключая свои)
+		database.DB.Preload("Settlement").
+			Scopes(visitFilter.Scope).
 			Where("is_approved = true").
+			Where("status = ?", models.PostStatusPublished).
 			Where("user_id != ?", userID).
 			Where("id NOT IN (?)",
 				database.DB.Table("posts").Select("id").Where("user_id = ?", userID),
 			).
-			Order("likes_count DESC").
+			Order("hot_score DESC, likes_count DESC").
 			Limit(limit).
 			Find(&posts)
 	} else {
 		// Основной запрос - посты от подписок
-		err := database.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id, username, image_url, bio")
-		}).
-			Preload("Settlement").
-			Preload("Photos", func(db *gorm.DB) *gorm.DB {
-				return db.Where("is_approved = true").Order("\"order\" ASC")
-			}).
-			Preload("Tags").
+		err := database.DB.Preload("Settlement").
 			Joins("JOIN followers ON followers.followed_id = posts.user_id").
 			Where("followers.follower_id = ?", userID).
+			Scopes(visitFilter.Scope).
 			Where("posts.is_approved = true").
+			Where("posts.status = ?", models.PostStatusPublished).
 			Where("posts.user_id != ?", userID).
 			Where("posts.id NOT IN (?)",
 				database.DB.Table("likes").Select("post_id").Where("user_id = ?", userID),
@@ -194,27 +200,28 @@ func GetFollowRecommendations(c *gin.Context) {
 	}
 
 	// Форматируем ответ
-	response := formatRecommendationResponse(posts)
+	response, err := formatRecommendationResponse(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"posts": response, "type": "follow"})
 }
 
-// formatRecommendationResponse - форматирует посты для ответа с рекомендациями
-func formatRecommendationResponse(posts []models.Post) []PostRecommendationResponse {
-	response := make([]PostRecommendationResponse, 0, len(posts))
+// formatRecommendationResponse - форматирует посты для ответа с рекомендациями; авторы, теги и обложки загружаются пакетом
+func formatRecommendationResponse(posts []models.Post) ([]PostRecommendationResponse, error) {
+	cards, err := feed.Cards(database.DB, posts)
+	if err != nil {
+		return nil, err
+	}
 
+	response := make([]PostRecommendationResponse, 0, len(posts))
 	for _, post := range posts {
-		// Получаем теги
-		tags := make([]string, 0)
-		for _, tag := range post.Tags {
-			tags = append(tags, tag.Name)
-		}
+		card := cards[post.ID]
 
-		// Получаем фото
-		photos := make([]PhotoResponse, 0)
-		if post.Photos != nil {
-			for _, photo := range post.Photos {
-				photos = append(photos, PhotoResponse{URL: photo.Url})
-			}
+		photos := make([]PhotoResponse, 0, 1)
+		if card.Cover != nil {
+			photos = append(photos, PhotoResponse{URL: card.Cover.Url, Width: card.Cover.Width, Height: card.Cover.Height})
 		}
 
 		// Получаем имя поселения - Settlement это встроенная структура, проверяем по ID
@@ -223,28 +230,24 @@ func formatRecommendationResponse(posts []models.Post) []PostRecommendationRespo
 			settlementName = post.Settlement.Name
 		}
 
-		// Получаем аватар и имя пользователя - User это встроенная структура, проверяем по ID
-		userAvatar := ""
-		userName := ""
-		if post.User.ID != 0 {
-			userName = post.User.Username
-			userAvatar = post.User.ImageUrl
-		}
-
 		response = append(response, PostRecommendationResponse{
 			ID:             post.ID,
 			Title:          post.Title,
 			CreatedAt:      post.CreatedAt.Format("2006-01-02 15:04:05"),
 			SettlementName: settlementName,
 			SettlementID:   post.SettlementID,
-			Tags:           tags,
+			Tags:           card.Tags,
 			Photos:         photos,
+			PhotosCount:    card.PhotosCount,
 			LikesCount:     post.LikesCount,
+			CommentsCount:  card.CommentsCount,
 			UserID:         uint(post.UserID),
-			UserAvatar:     userAvatar,
-			UserName:       userName,
+			UserAvatar:     card.Author.ImageUrl,
+			UserName:       card.Author.Username,
+			VisitedFrom:    post.VisitedFrom,
+			VisitedTo:      post.VisitedTo,
 		})
 	}
 
-	return response
+	return response, nil
 }
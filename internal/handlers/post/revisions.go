@@ -0,0 +1,536 @@
+package post
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevisionSnapshot - содержимое версии поста в удобном для клиента виде
+type RevisionSnapshot struct {
+	Version        int                `json:"version"`
+	EditorID       int                `json:"editor_id"`
+	EditorName     string             `json:"editor_name"`
+	Title          string             `json:"title"`
+	SettlementID   uint               `json:"settlement_id"`
+	SettlementName string             `json:"settlement_name"`
+	Paragraphs     []models.Paragraph `json:"paragraphs"`
+	Photos         []models.PostPhoto `json:"photos"`
+	Tags           []string           `json:"tags"`
+	Stops          []models.TripStop  `json:"stops"`
+	RestoredFrom   *int               `json:"restored_from,omitempty"`
+	CreatedAt      string             `json:"created_at"`
+}
+
+// ParagraphChange - одна операция в пофрагментном diff между версиями
+type ParagraphChange struct {
+	Op       string `json:"op"` // equal, added, removed
+	OldIndex *int   `json:"old_index,omitempty"`
+	NewIndex *int   `json:"new_index,omitempty"`
+	Content  string `json:"content"`
+}
+
+// createPostRevision сохраняет текущее состояние поста как следующую версию
+func createPostRevision(tx *gorm.DB, postID uint, editorID int, restoredFrom *int) (*models.PostRevision, error) {
+	var post models.Post
+	err := tx.
+		Preload("Paragraphs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("paragraphs.order ASC")
+		}).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("trip_stops.order ASC")
+		}).
+		First(&post, postID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	if err := tx.Table("tags").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id = ?", postID).
+		Order("post_tags.id ASC").
+		Pluck("tags.name", &tags).Error; err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	paragraphsJSON, _ := json.Marshal(post.Paragraphs)
+	photosJSON, _ := json.Marshal(post.Photos)
+	tagsJSON, _ := json.Marshal(tags)
+	stopsJSON, _ := json.Marshal(post.Stops)
+
+	var lastVersion int
+	if err := tx.Model(&models.PostRevision{}).
+		Where("post_id = ?", postID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&lastVersion).Error; err != nil {
+		return nil, err
+	}
+
+	revision := models.PostRevision{
+		PostID:         postID,
+		Version:        lastVersion + 1,
+		EditorID:       editorID,
+		Title:          post.Title,
+		SettlementID:   post.SettlementID,
+		SettlementName: post.SettlementName,
+		Paragraphs:     string(paragraphsJSON),
+		Photos:         string(photosJSON),
+		Tags:           string(tagsJSON),
+		Stops:          string(stopsJSON),
+		RestoredFrom:   restoredFrom,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// ensureInitialRevision сохраняет исходное состояние поста, если история ещё пуста
+func ensureInitialRevision(tx *gorm.DB, post models.Post) error {
+	var count int64
+	if err := tx.Model(&models.PostRevision{}).Where("post_id = ?", post.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := createPostRevision(tx, post.ID, post.UserID, nil)
+	return err
+}
+
+func revisionToSnapshot(revision models.PostRevision) RevisionSnapshot {
+	snapshot := RevisionSnapshot{
+		Version:        revision.Version,
+		EditorID:       revision.EditorID,
+		EditorName:     revision.Editor.Username,
+		Title:          revision.Title,
+		SettlementID:   revision.SettlementID,
+		SettlementName: revision.SettlementName,
+		Paragraphs:     []models.Paragraph{},
+		Photos:         []models.PostPhoto{},
+		Tags:           []string{},
+		Stops:          []models.TripStop{},
+		RestoredFrom:   revision.RestoredFrom,
+		CreatedAt:      revision.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if revision.Paragraphs != "" {
+		if err := json.Unmarshal([]byte(revision.Paragraphs), &snapshot.Paragraphs); err != nil {
+			log.Printf("Ошибка разбора параграфов версии %d: %v", revision.ID, err)
+		}
+	}
+	if revision.Photos != "" {
+		if err := json.Unmarshal([]byte(revision.Photos), &snapshot.Photos); err != nil {
+			log.Printf("Ошибка разбора фото версии %d: %v", revision.ID, err)
+		}
+	}
+	if revision.Tags != "" {
+		if err := json.Unmarshal([]byte(revision.Tags), &snapshot.Tags); err != nil {
+			log.Printf("Ошибка разбора тегов версии %d: %v", revision.ID, err)
+		}
+	}
+	if revision.Stops != "" {
+		if err := json.Unmarshal([]byte(revision.Stops), &snapshot.Stops); err != nil {
+			log.Printf("Ошибка разбора остановок версии %d: %v", revision.ID, err)
+		}
+	}
+
+	return snapshot
+}
+
+// diffParagraphs строит diff по параграфам на основе наибольшей общей подпоследовательности
+func diffParagraphs(oldParagraphs, newParagraphs []string) []ParagraphChange {
+	n, m := len(oldParagraphs), len(newParagraphs)
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldParagraphs[i] == newParagraphs[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	changes := make([]ParagraphChange, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		oldIndex, newIndex := i, j
+		switch {
+		case oldParagraphs[i] == newParagraphs[j]:
+			changes = append(changes, ParagraphChange{Op: "equal", OldIndex: &oldIndex, NewIndex: &newIndex, Content: newParagraphs[j]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, ParagraphChange{Op: "removed", OldIndex: &oldIndex, Content: oldParagraphs[i]})
+			i++
+		default:
+			changes = append(changes, ParagraphChange{Op: "added", NewIndex: &newIndex, Content: newParagraphs[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		oldIndex := i
+		changes = append(changes, ParagraphChange{Op: "removed", OldIndex: &oldIndex, Content: oldParagraphs[i]})
+	}
+	for ; j < m; j++ {
+		newIndex := j
+		changes = append(changes, ParagraphChange{Op: "added", NewIndex: &newIndex, Content: newParagraphs[j]})
+	}
+
+	return changes
+}
+
+func paragraphContents(paragraphs []models.Paragraph) []string {
+	contents := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		contents = append(contents, blockSignature(p))
+	}
+	return contents
+}
+
+// loadRevisionPost загружает пост и проверяет, что текущий пользователь - автор или соавтор
+func loadRevisionPost(c *gin.Context) (models.Post, int, bool) {
+	var post models.Post
+
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return post, 0, false
+	}
+
+	postID, err := strconv.ParseUint(c.Param("postID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return post, 0, false
+	}
+
+	if err := database.DB.First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return post, 0, false
+	}
+
+	if !isPostMember(database.DB, post, int(userID)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only post authors can view revisions"})
+		return post, 0, false
+	}
+
+	return post, int(userID), true
+}
+
+func findRevision(postID uint, version int) (models.PostRevision, error) {
+	var revision models.PostRevision
+	err := database.DB.
+		Preload("Editor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, username")
+		}).
+		Where("post_id = ? AND version = ?", postID, version).
+		First(&revision).Error
+	return revision, err
+}
+
+// GetPostRevisions - список версий поста
+func GetPostRevisions(c *gin.Context) {
+	post, _, ok := loadRevisionPost(c)
+	if !ok {
+		return
+	}
+
+	var revisions []models.PostRevision
+	if err := database.DB.
+		Preload("Editor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, username, image_url")
+		}).
+		Where("post_id = ?", post.ID).
+		Order("version DESC").
+		Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(revisions))
+	for _, rev := range revisions {
+		result = append(result, gin.H{
+			"version":         rev.Version,
+			"editor_id":       rev.EditorID,
+			"editor_name":     rev.Editor.Username,
+			"editor_avatar":   rev.Editor.ImageUrl,
+			"title":           rev.Title,
+			"settlement_name": rev.SettlementName,
+			"restored_from":   rev.RestoredFrom,
+			"created_at":      rev.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id":   post.ID,
+		"revisions": result,
+		"count":     len(result),
+	})
+}
+
+// GetPostRevision - содержимое одной версии поста
+func GetPostRevision(c *gin.Context) {
+	post, _, ok := loadRevisionPost(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	revision, err := findRevision(post.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisionToSnapshot(revision))
+}
+
+// GetPostRevisionDiff - пофрагментный diff между версиями ?from= и ?to=
+func GetPostRevisionDiff(c *gin.Context) {
+	post, _, ok := loadRevisionPost(c)
+	if !ok {
+		return
+	}
+
+	fromVersion, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' version"})
+		return
+	}
+	toVersion, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' version"})
+		return
+	}
+
+	fromRevision, err := findRevision(post.ID, fromVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision 'from' not found"})
+		return
+	}
+	toRevision, err := findRevision(post.ID, toVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision 'to' not found"})
+		return
+	}
+
+	from := revisionToSnapshot(fromRevision)
+	to := revisionToSnapshot(toRevision)
+
+	fields := gin.H{}
+	if from.Title != to.Title {
+		fields["title"] = gin.H{"from": from.Title, "to": to.Title}
+	}
+	if from.SettlementID != to.SettlementID {
+		fields["settlement"] = gin.H{
+			"from": gin.H{"id": from.SettlementID, "name": from.SettlementName},
+			"to":   gin.H{"id": to.SettlementID, "name": to.SettlementName},
+		}
+	}
+
+	addedTags, removedTags := diffStringSets(from.Tags, to.Tags)
+
+	addedPhotos, removedPhotos := diffStringSets(photoUrls(from.Photos), photoUrls(to.Photos))
+	addedStops, removedStops := diffStringSets(stopNames(from.Stops), stopNames(to.Stops))
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id":    post.ID,
+		"from":       fromVersion,
+		"to":         toVersion,
+		"fields":     fields,
+		"paragraphs": diffParagraphs(paragraphContents(from.Paragraphs), paragraphContents(to.Paragraphs)),
+		"tags":       gin.H{"added": addedTags, "removed": removedTags},
+		"photos":     gin.H{"added": addedPhotos, "removed": removedPhotos},
+		"stops":      gin.H{"added": addedStops, "removed": removedStops},
+	})
+}
+
+// diffStringSets возвращает элементы, появившиеся и исчезнувшие между двумя списками
+func diffStringSets(oldItems, newItems []string) ([]string, []string) {
+	oldSet := make(map[string]bool, len(oldItems))
+	for _, item := range oldItems {
+		oldSet[item] = true
+	}
+	newSet := make(map[string]bool, len(newItems))
+	for _, item := range newItems {
+		newSet[item] = true
+	}
+
+	added := make([]string, 0)
+	for _, item := range newItems {
+		if !oldSet[item] {
+			added = append(added, item)
+		}
+	}
+	removed := make([]string, 0)
+	for _, item := range oldItems {
+		if !newSet[item] {
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
+
+// snapshotUpdateRequest - восстановление версии как запрос на обновление, для проверки конфликта версий
+func snapshotUpdateRequest(snapshot RevisionSnapshot) PostUpdateRequest {
+	stops := make([]TripStopRequest, 0, len(snapshot.Stops))
+	for _, stop := range snapshot.Stops {
+		stops = append(stops, TripStopRequest{SettlementID: stop.SettlementID, SettlementName: stop.SettlementName})
+	}
+	return PostUpdateRequest{
+		Title:          snapshot.Title,
+		SettlementID:   snapshot.SettlementID,
+		SettlementName: snapshot.SettlementName,
+		Tags:           snapshot.Tags,
+		Paragraphs:     snapshot.Paragraphs,
+		Photos:         snapshot.Photos,
+		Stops:          stops,
+	}
+}
+
+// RestorePostRevision - восстанавливает старую версию поста, сохраняя её как новую версию
+func RestorePostRevision(c *gin.Context) {
+	post, userID, ok := loadRevisionPost(c)
+	if !ok {
+		return
+	}
+
+	if !canEditPost(database.DB, post, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner and editors can restore revisions"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	revision, err := findRevision(post.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+	snapshot := revisionToSnapshot(revision)
+
+	// Версия, от которой восстанавливает клиент: поле version в теле или заголовок If-Match
+	var input struct {
+		Version *int `json:"version"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	expectedVersion, hasExpectedVersion, err := expectedPostVersion(c, PostUpdateRequest{Version: input.Version})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var newRevision *models.PostRevision
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку поста, чтобы восстановление не пересекалось с сохранением или другим восстановлением
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, "id = ?", post.ID).Error; err != nil {
+			return err
+		}
+
+		if hasExpectedVersion {
+			if conflict := detectVersionConflict(tx, post, expectedVersion, snapshotUpdateRequest(snapshot)); conflict != nil {
+				return conflict
+			}
+		}
+
+		if err := tx.Model(&models.Post{}).Where("id = ?", post.ID).Updates(map[string]interface{}{
+			"title":           snapshot.Title,
+			"settlement_id":   snapshot.SettlementID,
+			"settlement_name": snapshot.SettlementName,
+		}).Error; err != nil {
+			return err
+		}
+
+		post.Title = snapshot.Title
+		if err := updatePostSlug(tx, &post); err != nil {
+			return err
+		}
+
+		if err := replacePostParagraphs(tx, post.ID, snapshot.Paragraphs); err != nil {
+			return err
+		}
+		if err := replacePostPhotos(tx, post.ID, snapshot.Photos); err != nil {
+			return err
+		}
+		if err := replacePostTags(tx, post.ID, snapshot.Tags); err != nil {
+			return err
+		}
+
+		distanceKm, err := routeDistanceForStops(tx, snapshot.Stops)
+		if err != nil {
+			return err
+		}
+		if err := replaceTripStops(tx, post.ID, snapshot.Stops, distanceKm); err != nil {
+			return err
+		}
+		if err := updatePostPreview(tx, post.ID); err != nil {
+			return err
+		}
+		if err := updatePostSearchDocument(tx, post.ID); err != nil {
+			return err
+		}
+		if _, err := checkPostDuplicates(tx, post); err != nil {
+			return err
+		}
+
+		newRevision, err = createPostRevision(tx, post.ID, userID, &version)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Post{}).Where("id = ?", post.ID).Update("version", newRevision.Version).Error
+	})
+
+	var conflict *versionConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, conflict.response())
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка восстановления версии %d поста %d: %v", version, post.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Revision restored",
+		"version":       newRevision.Version,
+		"restored_from": version,
+	})
+}
@@ -0,0 +1,368 @@
+package post
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	"padaroja/internal/feed"
+	"padaroja/internal/handlers/notification"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Сколько поисков может сохранить один пользователь
+const maxSavedSearchesPerUser = 20
+
+// SavedSearchRequest - сохранённый поиск: название и те же параметры, что у ленты
+type SavedSearchRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Query            string   `json:"query"`
+	Tags             string   `json:"tags"`
+	TagsMode         string   `json:"tags_mode"` // all (по умолчанию) или any
+	Admin1Code       string   `json:"admin1_code"`
+	Admin2Code       string   `json:"admin2_code"`
+	Lat              *float64 `json:"lat"`
+	Lon              *float64 `json:"lon"`
+	NearSettlementID *uint    `json:"near_settlement_id"`
+	RadiusKm         float64  `json:"radius_km"`
+}
+
+// savedSearchFromRequest проверяет запрос так же, как параметры ленты, и заполняет поля поиска
+func savedSearchFromRequest(input SavedSearchRequest, search *models.SavedSearch) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return fmt.Errorf("name must be 1-100 characters")
+	}
+	query := strings.TrimSpace(input.Query)
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return fmt.Errorf("search query is too long")
+	}
+
+	params := map[string]string{
+		"tags":        input.Tags,
+		"tags_mode":   input.TagsMode,
+		"admin1_code": input.Admin1Code,
+		"admin2_code": input.Admin2Code,
+	}
+	if input.Lat != nil {
+		params["lat"] = strconv.FormatFloat(*input.Lat, 'f', -1, 64)
+	}
+	if input.Lon != nil {
+		params["lon"] = strconv.FormatFloat(*input.Lon, 'f', -1, 64)
+	}
+	if input.NearSettlementID != nil {
+		params["near_settlement_id"] = strconv.FormatUint(uint64(*input.NearSettlementID), 10)
+	}
+	if input.RadiusKm != 0 {
+		params["radius_km"] = strconv.FormatFloat(input.RadiusKm, 'f', -1, 64)
+	}
+	filter, err := parseFeedFilter(func(key string) string { return params[key] })
+	if err != nil {
+		return err
+	}
+
+	tags := strings.TrimSpace(filter.Tags)
+	if query == "" && tags == "" && filter.Admin1Code == "" && filter.RadiusKm == 0 {
+		return fmt.Errorf("saved search needs a query, tags, region or radius")
+	}
+
+	search.Name = name
+	search.Query = query
+	search.Tags = tags
+	search.TagsAny = filter.TagsAny
+	search.Admin1Code = filter.Admin1Code
+	search.Admin2Code = filter.Admin2Code
+	search.Latitude, search.Longitude, search.RadiusKm = nil, nil, 0
+	if filter.RadiusKm > 0 {
+		lat, lon := filter.Lat, filter.Lon
+		search.Latitude, search.Longitude, search.RadiusKm = &lat, &lon, filter.RadiusKm
+	}
+	return nil
+}
+
+func parseSavedSearchID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("searchID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetSavedSearches - сохранённые поиски пользователя
+func GetSavedSearches(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var searches []models.SavedSearch
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&searches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_searches": searches})
+}
+
+// CreateSavedSearch сохраняет запрос ленты под названием
+func CreateSavedSearch(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input SavedSearchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search := models.SavedSearch{UserID: int(userID)}
+	if err := savedSearchFromRequest(input, &search); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.SavedSearch{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxSavedSearchesPerUser {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can save at most %d searches", maxSavedSearchesPerUser)})
+		return
+	}
+
+	if err := database.DB.Create(&search).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// UpdateSavedSearch меняет название и параметры сохранённого поиска
+func UpdateSavedSearch(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	searchID, ok := parseSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	var input SavedSearchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var search models.SavedSearch
+	if err := database.DB.Where("id = ? AND user_id = ?", searchID, userID).First(&search).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err := savedSearchFromRequest(input, &search); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Save(&search).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch удаляет сохранённый поиск; полученные по нему уведомления остаются
+func DeleteSavedSearch(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	searchID, ok := parseSavedSearchID(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND user_id = ?", searchID, userID).Delete(&models.SavedSearch{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+
+	database.DB.Model(&models.Notification{}).Where("saved_search_id = ?", searchID).Update("saved_search_id", nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted"})
+}
+
+// savedSearchCandidates выбирает сохранённые поиски, под которые подходит пост, одним запросом:
+// место (область, район, радиус вокруг точки) и полнотекстовый запрос проверяются в SQL так же, как в ленте.
+// Теги проверяет matchesSavedSearchTags. Владельцы, уже получившие уведомление о посте, пропускаются.
+func savedSearchCandidates(post models.Post) ([]models.SavedSearch, error) {
+	// Места поста: основной населённый пункт и остановки маршрута
+	var places []models.Settlement
+	if err := database.DB.Select("geonameid, latitude, longitude, admin1_code, admin2_code").
+		Where("geonameid = ? OR geonameid IN (SELECT settlement_id FROM trip_stops WHERE post_id = ?)", post.SettlementID, post.ID).
+		Find(&places).Error; err != nil {
+		return nil, err
+	}
+
+	query := database.DB.
+		Where("user_id != ?", post.UserID).
+		Where("user_id NOT IN (SELECT user_id FROM notifications WHERE post_id = ? AND type = ?)", post.ID, models.NotificationSavedSearch).
+		Where("saved_searches.query = '' OR EXISTS (SELECT 1 FROM post_search_documents WHERE post_search_documents.post_id = ? "+
+			"AND post_search_documents.document @@ "+strings.ReplaceAll(searchQuerySQL, "?", "saved_searches.query")+")", post.ID)
+
+	if len(places) == 0 {
+		query = query.Where("admin1_code = '' AND radius_km = 0")
+	} else {
+		regions := make([]string, 0, len(places))
+		districts := make([][]interface{}, 0, len(places))
+		var nearby []string
+		var nearbyArgs []interface{}
+		for _, place := range places {
+			regions = append(regions, place.Admin1Code)
+			districts = append(districts, []interface{}{place.Admin1Code, place.Admin2Code})
+			// Расстояние симметрично: считаем его от центра поиска до места поста
+			nearby = append(nearby, utils.HaversineSQL("latitude", "longitude")+" <= radius_km")
+			nearbyArgs = append(nearbyArgs, place.Latitude, place.Latitude, place.Longitude)
+		}
+		query = query.
+			Where("admin1_code = '' OR admin1_code IN ?", regions).
+			Where("admin2_code = '' OR (admin1_code, admin2_code) IN ?", districts).
+			Where("radius_km = 0 OR "+strings.Join(nearby, " OR "), nearbyArgs...)
+	}
+
+	var searches []models.SavedSearch
+	err := query.Order("user_id, id").Find(&searches).Error
+	return searches, err
+}
+
+// matchesSavedSearchTags проверяет теги сохранённого поиска по правилам applyTagFilter: известный тег или синоним -
+// точное совпадение, иначе - начало названия тега. resolved - основные теги для названий из поисков (resolveTagNames).
+func matchesSavedSearchTags(search models.SavedSearch, postTags []models.Tags, resolved map[string]uint) bool {
+	matched, total := 0, 0
+	for _, raw := range strings.Split(search.Tags, ",") {
+		name := utils.NormalizeTagName(raw)
+		if name == "" {
+			continue
+		}
+		total++
+
+		tagID, known := resolved[name]
+		for _, tag := range postTags {
+			if known && tag.ID == tagID || !known && strings.HasPrefix(tag.Name, name) {
+				matched++
+				break
+			}
+		}
+	}
+	if total == 0 {
+		return true
+	}
+	if search.TagsAny {
+		return matched > 0
+	}
+	return matched == total
+}
+
+// notifySavedSearches уведомляет владельцев сохранённых поисков, под которые подходит только что опубликованный пост.
+// Пользователь получает одно уведомление на пост, даже если пост подошёл под несколько его поисков.
+func notifySavedSearches(post models.Post) {
+	go func() {
+		var published int64
+		database.DB.Model(&models.Post{}).
+			Where("id = ? AND is_approved = ? AND status = ?", post.ID, true, models.PostStatusPublished).
+			Count(&published)
+		if published == 0 {
+			return
+		}
+
+		searches, err := savedSearchCandidates(post)
+		if err != nil {
+			log.Printf("Ошибка выборки сохранённых поисков для поста %d: %v", post.ID, err)
+			return
+		}
+		if len(searches) == 0 {
+			return
+		}
+
+		// Теги поста и основные теги для названий из поисков - по запросу на всех кандидатов
+		var postTags []models.Tags
+		var tagNames []string
+		for _, search := range searches {
+			for _, raw := range strings.Split(search.Tags, ",") {
+				if name := utils.NormalizeTagName(raw); name != "" {
+					tagNames = append(tagNames, name)
+				}
+			}
+		}
+		resolved := map[string]uint{}
+		if len(tagNames) > 0 {
+			if err := database.DB.Select("tags.id, tags.name").
+				Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+				Where("post_tags.post_id = ?", post.ID).
+				Find(&postTags).Error; err != nil {
+				log.Printf("Ошибка загрузки тегов поста %d: %v", post.ID, err)
+				return
+			}
+			if resolved, err = resolveTagNames(database.DB, tagNames); err != nil {
+				log.Printf("Ошибка загрузки тегов сохранённых поисков: %v", err)
+				return
+			}
+		}
+
+		cards, err := feed.Cards(database.DB, []models.Post{post})
+		if err != nil {
+			log.Printf("Ошибка загрузки карточки поста %d: %v", post.ID, err)
+		}
+		card := cards[post.ID]
+		postData := gin.H{
+			"id":              post.ID,
+			"title":           post.Title,
+			"settlement_name": post.SettlementName,
+			"photos":          card.Photos(),
+			"user_id":         post.UserID,
+			"user_name":       card.AuthorName(),
+			"user_avatar":     card.Author.ImageUrl,
+		}
+
+		notified := make(map[int]bool)
+		for _, search := range searches {
+			if notified[search.UserID] || !matchesSavedSearchTags(search, postTags, resolved) {
+				continue
+			}
+			notified[search.UserID] = true
+
+			postID, searchID := post.ID, search.ID
+			n := models.Notification{
+				UserID:        search.UserID,
+				Type:          models.NotificationSavedSearch,
+				PostID:        &postID,
+				SavedSearchID: &searchID,
+				Text:          fmt.Sprintf("Новый пост «%s» по поиску «%s»", post.Title, search.Name),
+			}
+			if err := notification.Create(database.DB, &n, gin.H{"post": postData, "saved_search_name": search.Name}); err != nil {
+				log.Printf("Ошибка сохранения уведомления для пользователя %d: %v", search.UserID, err)
+			}
+		}
+		if len(notified) > 0 {
+			log.Printf("🔔 Пост %d подошёл под сохранённые поиски %d пользователей", post.ID, len(notified))
+		}
+	}()
+}
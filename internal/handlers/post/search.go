@@ -0,0 +1,311 @@
+package post
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Запрос читателя в двух конфигурациях: русская находит словоформы, простая - имена и слова на других языках
+	searchQuerySQL = "(websearch_to_tsquery('russian', ?) || websearch_to_tsquery('simple', ?))"
+	// Оценка релевантности целым числом, чтобы её можно было сохранить в курсоре и сравнивать без погрешности
+	searchRankSQL = "(ts_rank_cd(post_search_documents.document, " + searchQuerySQL + ", 32) * 1000000)::bigint"
+
+	searchTitleHeadline   = "HighlightAll=true, StartSel=<mark>, StopSel=</mark>"
+	searchContentHeadline = "MaxFragments=2, MaxWords=30, MinWords=12, FragmentDelimiter=\" … \", StartSel=<mark>, StopSel=</mark>"
+
+	maxSearchQueryLength = 200
+)
+
+// searchWeightSQL - часть документа с весом: A - заголовки, B - места и теги, C - текст
+func searchWeightSQL(weight string) string {
+	return "setweight(to_tsvector('russian', ?) || to_tsvector('simple', ?), '" + weight + "')"
+}
+
+// updatePostSearchDocument пересобирает поисковый документ поста после сохранения поста или перевода
+func updatePostSearchDocument(tx *gorm.DB, postID uint) error {
+	var post models.Post
+	if err := tx.Unscoped().
+		Preload("Settlement").
+		Preload("Stops.Settlement").
+		First(&post, postID).Error; err != nil {
+		return err
+	}
+
+	var paragraphs []models.Paragraph
+	if err := tx.Where("post_id = ?", postID).Order("paragraphs.order ASC").Find(&paragraphs).Error; err != nil {
+		return err
+	}
+	var translations []models.PostTranslation
+	if err := tx.Where("post_id = ?", postID).Order("language ASC").Find(&translations).Error; err != nil {
+		return err
+	}
+	var tags []string
+	if err := tx.Table("tags").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id = ?", postID).
+		Pluck("tags.name", &tags).Error; err != nil {
+		return err
+	}
+
+	titles := []string{post.Title}
+	places := []string{post.SettlementName, post.Settlement.Name, extractRussianName(post.Settlement.Alternatenames)}
+	for _, stop := range post.Stops {
+		places = append(places, stop.SettlementName, stop.Settlement.Name)
+	}
+
+	// Подписи меток на карте - названия мест, остальные блоки - текст
+	var body []string
+	addBlocks := func(blocks []models.Paragraph) {
+		for _, p := range blocks {
+			text := utils.MarkdownToPlain(p.Content)
+			if text == "" {
+				continue
+			}
+			if p.Type == models.BlockMapPin {
+				places = append(places, text)
+			} else {
+				body = append(body, text)
+			}
+		}
+	}
+	addBlocks(paragraphs)
+	for _, t := range translations {
+		titles = append(titles, t.Title)
+		addBlocks(decodeTranslationParagraphs(t))
+	}
+
+	parts := []struct {
+		weight string
+		text   string
+	}{
+		{"A", strings.Join(titles, " ")},
+		{"B", strings.Join(places, " ")},
+		{"B", strings.Join(tags, " ")},
+		{"C", strings.Join(body, "\n")},
+	}
+	documentSQL := make([]string, 0, len(parts))
+	args := []interface{}{postID}
+	for _, part := range parts {
+		documentSQL = append(documentSQL, searchWeightSQL(part.weight))
+		args = append(args, part.text, part.text)
+	}
+	args = append(args, strings.Join(body, "\n"), time.Now())
+
+	return tx.Exec(`
+		INSERT INTO post_search_documents (post_id, document, content, updated_at)
+		VALUES (?, `+strings.Join(documentSQL, " || ")+`, ?, ?)
+		ON CONFLICT (post_id) DO UPDATE SET
+			document = EXCLUDED.document,
+			content = EXCLUDED.content,
+			updated_at = EXCLUDED.updated_at
+	`, args...).Error
+}
+
+// UpdatePostSearchDocuments пересобирает поисковые документы нескольких постов, например после слияния тегов
+func UpdatePostSearchDocuments(tx *gorm.DB, postIDs []uint) error {
+	for _, id := range postIDs {
+		if err := updatePostSearchDocument(tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchHighlight экранирует отрывок из ts_headline, оставляя только подсветку <mark>
+func searchHighlight(headline string) string {
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").Replace(escaped)
+}
+
+// SearchResultResponse - пост в выдаче поиска: карточка ленты, подсветка и релевантность
+type SearchResultResponse struct {
+	PostResponse
+	TitleHighlight string  `json:"title_highlight"`
+	Highlight      string  `json:"highlight"`
+	Rank           float64 `json:"rank"`
+}
+
+// SearchPosts - полнотекстовый поиск по опубликованным постам.
+// Параметры: q (обязателен), sort (relevance - по умолчанию, new), фильтры ленты и дат поездки, cursor, limit.
+func SearchPosts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is too long"})
+		return
+	}
+
+	page, err := utils.ParsePage(c.Query, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sortBy := c.DefaultQuery("sort", "relevance")
+	if sortBy != "new" {
+		sortBy = "relevance"
+	}
+	if page.Cursor != nil && page.Cursor.Sort != sortBy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidCursor.Error()})
+		return
+	}
+
+	visitFilter, err := utils.ParseVisitFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filterScope, err := feedFilterScope(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.DB.Model(&models.Post{}).
+		Joins("JOIN post_search_documents ON post_search_documents.post_id = posts.id").
+		Where("posts.is_approved = ? AND posts.status = ?", true, models.PostStatusPublished).
+		Where("post_search_documents.document @@ "+searchQuerySQL, query, query).
+		Scopes(filterScope, visitFilter.Scope)
+
+	if sortBy == "new" {
+		db = db.Scopes(newestFirst(page))
+	} else {
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                searchRankSQL + " DESC, posts.created_at DESC, posts.id DESC",
+			Vars:               []interface{}{query, query},
+			WithoutParentheses: true,
+		}})
+		if page.Cursor != nil {
+			db = db.Where(utils.KeysetSQL(searchRankSQL, "posts.created_at", "posts.id"),
+				query, query, page.Cursor.Score, page.Cursor.Time, page.Cursor.ID)
+		}
+		db = db.Limit(page.Limit + 1)
+	}
+
+	var hits []struct {
+		ID        uint
+		CreatedAt time.Time
+		Rank      int64
+	}
+	if err := db.Select("posts.id, posts.created_at, "+searchRankSQL+" AS rank", query, query).
+		Scan(&hits).Error; err != nil {
+		log.Printf("Ошибка поиска по запросу '%s': %v", query, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+
+	hits, hasMore := utils.TrimPage(hits, page.Limit)
+	var nextCursor *string
+	if hasMore {
+		last := hits[len(hits)-1]
+		cursor := utils.Cursor{Sort: sortBy, Time: last.CreatedAt, ID: last.ID}
+		if sortBy == "relevance" {
+			cursor.Score = last.Rank
+		}
+		nextCursor = utils.StringPtr(utils.EncodeCursor(cursor))
+	}
+
+	results := make([]SearchResultResponse, 0, len(hits))
+	if len(hits) == 0 {
+		c.JSON(http.StatusOK, gin.H{"query": query, "results": results, "next_cursor": nextCursor})
+		return
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	// Подсветка считается только для постов страницы
+	var headlines []struct {
+		ID             uint
+		TitleHighlight string
+		Highlight      string
+	}
+	database.DB.Table("posts").
+		Select("posts.id, ts_headline('russian', posts.title, "+searchQuerySQL+", ?) AS title_highlight, "+
+			"ts_headline('russian', post_search_documents.content, "+searchQuerySQL+", ?) AS highlight",
+			query, query, searchTitleHeadline, query, query, searchContentHeadline).
+		Joins("JOIN post_search_documents ON post_search_documents.post_id = posts.id").
+		Where("posts.id IN ?", ids).
+		Scan(&headlines)
+	headlineByID := make(map[uint]int, len(headlines))
+	for i, h := range headlines {
+		headlineByID[h.ID] = i
+	}
+
+	var found []models.Post
+	if err := database.DB.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+	postByID := make(map[uint]models.Post, len(found))
+	for _, p := range found {
+		postByID[p.ID] = p
+	}
+	posts := make([]models.Post, 0, len(hits))
+	ranks := make(map[uint]int64, len(hits))
+	for _, hit := range hits {
+		if p, ok := postByID[hit.ID]; ok {
+			posts = append(posts, p)
+			ranks[hit.ID] = hit.Rank
+		}
+	}
+
+	// Подсветка - по тексту оригинала, поэтому карточки не переводятся
+	cards, err := feedPostResponses(c, posts, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+	for _, card := range cards {
+		item := SearchResultResponse{PostResponse: card, Rank: float64(ranks[card.ID]) / 1000000}
+		if i, ok := headlineByID[card.ID]; ok {
+			item.TitleHighlight = searchHighlight(headlines[i].TitleHighlight)
+			item.Highlight = searchHighlight(headlines[i].Highlight)
+		}
+		results = append(results, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":       query,
+		"results":     results,
+		"next_cursor": nextCursor,
+	})
+}
+
+// BackfillPostSearchDocuments собирает поисковые документы постов, сохранённых до появления поиска
+func BackfillPostSearchDocuments() {
+	var postIDs []uint
+	if err := database.DB.Unscoped().Model(&models.Post{}).
+		Where("id NOT IN (SELECT post_id FROM post_search_documents)").
+		Order("id ASC").
+		Pluck("id", &postIDs).Error; err != nil {
+		log.Printf("Ошибка выборки постов без поискового документа: %v", err)
+		return
+	}
+
+	for _, id := range postIDs {
+		if err := updatePostSearchDocument(database.DB, id); err != nil {
+			log.Printf("Не удалось собрать поисковый документ поста %d: %v", id, err)
+		}
+	}
+	if len(postIDs) > 0 {
+		log.Printf("🔎 Собраны поисковые документы для %d постов", len(postIDs))
+	}
+}
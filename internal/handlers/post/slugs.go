@@ -0,0 +1,139 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Сколько вариантов с числовым суффиксом перебирать, прежде чем сдаться
+const maxSlugAttempts = 1000
+
+var errSlugUnavailable = errors.New("could not generate a unique slug")
+
+// uniquePostSlug подбирает свободный slug для поста автора: base, base-2, base-3...
+// Занятыми считаются текущие slug других постов автора и их прежние slug (чтобы старые ссылки не сменили пост).
+func uniquePostSlug(tx *gorm.DB, userID int, postID uint, base string) (string, error) {
+	for i := 1; i <= maxSlugAttempts; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		var taken int64
+		// Посты в корзине тоже держат свой slug - его получат обратно при восстановлении
+		if err := tx.Unscoped().Model(&models.Post{}).
+			Where("user_id = ? AND slug = ? AND id <> ?", userID, candidate, postID).
+			Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken > 0 {
+			continue
+		}
+		if err := tx.Model(&models.PostSlugHistory{}).
+			Where("user_id = ? AND slug = ? AND post_id <> ?", userID, candidate, postID).
+			Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return candidate, nil
+		}
+	}
+	return "", errSlugUnavailable
+}
+
+// updatePostSlug пересчитывает slug после смены заголовка; прежний slug сохраняется в истории
+func updatePostSlug(tx *gorm.DB, post *models.Post) error {
+	slug, err := uniquePostSlug(tx, post.UserID, post.ID, utils.Slugify(post.Title, postLanguage(*post)))
+	if err != nil {
+		return err
+	}
+	if slug == post.Slug {
+		return nil
+	}
+
+	if post.Slug != "" {
+		history := models.PostSlugHistory{PostID: post.ID, UserID: post.UserID, Slug: post.Slug}
+		if err := tx.Where(history).FirstOrCreate(&history).Error; err != nil {
+			return err
+		}
+	}
+	// Заголовок вернули к прежнему - slug снова текущий, а не исторический
+	if err := tx.Where("post_id = ? AND slug = ?", post.ID, slug).Delete(&models.PostSlugHistory{}).Error; err != nil {
+		return err
+	}
+
+	post.Slug = slug
+	return tx.Model(&models.Post{}).Where("id = ?", post.ID).Update("slug", slug).Error
+}
+
+// BackfillPostSlugs заполняет slug у постов, созданных до появления адресов по slug
+func BackfillPostSlugs() {
+	var posts []models.Post
+	if err := database.DB.Select("id, user_id, title, language, slug").
+		Where("slug = '' OR slug IS NULL").
+		Order("id ASC").
+		Find(&posts).Error; err != nil {
+		log.Printf("Ошибка выборки постов без slug: %v", err)
+		return
+	}
+
+	for i := range posts {
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return updatePostSlug(tx, &posts[i])
+		}); err != nil {
+			log.Printf("Не удалось создать slug для поста %d: %v", posts[i].ID, err)
+		}
+	}
+	if len(posts) > 0 {
+		log.Printf("🔗 Созданы slug для %d постов", len(posts))
+	}
+}
+
+// GetPostBySlug - пост по имени автора и slug; по прежнему slug отвечает редиректом на текущий адрес
+func GetPostBySlug(c *gin.Context) {
+	var author models.User
+	if err := database.DB.Select("id, username").
+		Where("username = ?", c.Param("username")).
+		First(&author).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	slug := c.Param("slug")
+
+	var post models.Post
+	err := database.DB.Select("id").Where("user_id = ? AND slug = ?", author.ID, slug).First(&post).Error
+	if err == nil {
+		respondPostDetail(c, post.ID)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var history models.PostSlugHistory
+	if err := database.DB.Where("user_id = ? AND slug = ?", author.ID, slug).
+		Preload("Post", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, slug")
+		}).
+		First(&history).Error; err != nil || history.Post.Slug == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	location := fmt.Sprintf("/api/posts/slug/%s/%s", url.PathEscape(author.Username), history.Post.Slug)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
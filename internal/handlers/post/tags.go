@@ -0,0 +1,123 @@
+package post
+
+import (
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// resolveTagNames находит основные теги для нормализованных названий двумя запросами, как utils.FindTag для каждого:
+// сначала по названию тега, затем по синониму. Неизвестных названий в результате нет.
+func resolveTagNames(tx *gorm.DB, names []string) (map[string]uint, error) {
+	resolved := make(map[string]uint, len(names))
+	if len(names) == 0 {
+		return resolved, nil
+	}
+
+	var tags []models.Tags
+	if err := tx.Select("id, name").Where("name IN ?", names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		resolved[tag.Name] = tag.ID
+	}
+
+	var synonyms []models.TagSynonym
+	if err := tx.Joins("JOIN tags ON tags.id = tag_synonyms.tag_id").
+		Where("tag_synonyms.name IN ?", names).
+		Find(&synonyms).Error; err != nil {
+		return nil, err
+	}
+	for _, synonym := range synonyms {
+		if _, ok := resolved[synonym.Name]; !ok {
+			resolved[synonym.Name] = synonym.TagID
+		}
+	}
+	return resolved, nil
+}
+
+// resolveTags приводит названия тегов к основным тегам: нормализация, синонимы, создание новых.
+// Повторы ("Замок", "замок ", "zamok" при синониме) схлопываются в один тег.
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tags, error) {
+	tags := make([]models.Tags, 0, len(names))
+	seen := make(map[uint]bool, len(names))
+
+	for _, raw := range names {
+		name := utils.NormalizeTagName(raw)
+		if name == "" {
+			continue
+		}
+
+		tag, _, ok := utils.FindTag(tx, name)
+		if !ok {
+			if err := tx.Where("name = ?", name).FirstOrCreate(&tag, models.Tags{Name: name}).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		if seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// normalizeTagNames - названия тегов в каноничном виде без повторов (для сравнения версий поста)
+func normalizeTagNames(tx *gorm.DB, names []string) []string {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, raw := range names {
+		name := utils.NormalizeTagName(raw)
+		if name == "" {
+			continue
+		}
+		if tag, _, ok := utils.FindTag(tx, name); ok {
+			name = tag.Name
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// applyTagFilter фильтрует посты по тегам из параметра tags (через запятую):
+// при matchAny достаточно одного из тегов, иначе пост должен иметь все теги.
+// Известный тег или синоним ищется точно, иначе - по началу названия тега.
+func applyTagFilter(db *gorm.DB, query string, matchAny bool) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	for _, raw := range strings.Split(query, ",") {
+		name := utils.NormalizeTagName(raw)
+		if name == "" {
+			continue
+		}
+
+		if tag, _, ok := utils.FindTag(database.DB, name); ok {
+			conditions = append(conditions, "posts.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)")
+			args = append(args, tag.ID)
+			continue
+		}
+		conditions = append(conditions, `posts.id IN (
+			SELECT post_tags.post_id FROM post_tags
+			JOIN tags ON tags.id = post_tags.tag_id
+			WHERE tags.name LIKE ? ESCAPE '\'
+		)`)
+		args = append(args, utils.EscapeLike(name)+"%")
+	}
+	if len(conditions) == 0 {
+		return db
+	}
+
+	operator := " AND "
+	if matchAny {
+		operator = " OR "
+	}
+	return db.Where("("+strings.Join(conditions, operator)+")", args...)
+}
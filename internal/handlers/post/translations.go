@@ -0,0 +1,446 @@
+package post
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Языки, на которых пишутся посты; первый - язык по умолчанию
+var supportedLanguages = []string{"ru", "be", "en"}
+
+// Длина заголовка перевода ограничена размером столбца, как у заголовка поста
+const maxTranslationTitleLength = 200
+
+// langOriginal - значение параметра lang, запрашивающее оригинал поста (например, для редактора)
+const langOriginal = "original"
+
+var (
+	errTranslationIsOriginal  = errors.New("translation language matches the original language of the post")
+	errLanguageHasTranslation = errors.New("post already has a translation in this language; delete it before changing the original language")
+)
+
+type TranslationRequest struct {
+	Title      string             `json:"title" binding:"required"`
+	Paragraphs []models.Paragraph `json:"paragraphs"`
+}
+
+// TranslationResponse - перевод поста; у оригинала IsOriginal = true
+type TranslationResponse struct {
+	Language   string             `json:"language"`
+	Title      string             `json:"title"`
+	Paragraphs []models.Paragraph `json:"paragraphs"`
+	IsOriginal bool               `json:"is_original"`
+	UpdatedBy  int                `json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time         `json:"updated_at,omitempty"`
+}
+
+// normalizeLanguage приводит тег языка ("be-BY", "EN") к поддерживаемому коду; пустая строка - язык не поддерживается
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, lang := range supportedLanguages {
+		if tag == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// requestedLanguages - языки читателя в порядке предпочтения: сначала параметр lang, затем Accept-Language.
+// Второе значение - запрошен ли явно оригинал (lang=original).
+func requestedLanguages(c *gin.Context) ([]string, bool) {
+	// Ответ зависит от языка читателя - кэши должны хранить варианты для разных Accept-Language
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	if lang := strings.ToLower(strings.TrimSpace(c.Query("lang"))); lang != "" {
+		if lang == langOriginal {
+			return nil, true
+		}
+		if normalized := normalizeLanguage(lang); normalized != "" {
+			return []string{normalized}, false
+		}
+	}
+
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var entries []weighted
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(part, ";")
+		lang := normalizeLanguage(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			entries = append(entries, weighted{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	languages := make([]string, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if !seen[e.lang] {
+			seen[e.lang] = true
+			languages = append(languages, e.lang)
+		}
+	}
+	return languages, false
+}
+
+// pickLanguage выбирает первый из запрошенных языков, на котором есть пост; иначе оригинал
+func pickLanguage(requested []string, original string, available map[string]bool) string {
+	for _, lang := range requested {
+		if lang == original || available[lang] {
+			return lang
+		}
+	}
+	return original
+}
+
+// postLanguage - язык оригинала; у постов до появления переводов он не заполнен
+func postLanguage(post models.Post) string {
+	if post.Language == "" {
+		return supportedLanguages[0]
+	}
+	return post.Language
+}
+
+func decodeTranslationParagraphs(translation models.PostTranslation) []models.Paragraph {
+	paragraphs := make([]models.Paragraph, 0)
+	if translation.Paragraphs != "" {
+		json.Unmarshal([]byte(translation.Paragraphs), &paragraphs)
+	}
+	return paragraphs
+}
+
+// localizePost подменяет заголовок и параграфы поста переводом на язык читателя.
+// Возвращает выбранный язык, список всех языков поста (оригинал первым) и время последнего изменения переводов.
+func localizePost(db *gorm.DB, c *gin.Context, post *models.Post) (string, []string, time.Time) {
+	original := postLanguage(*post)
+
+	var translations []models.PostTranslation
+	db.Where("post_id = ?", post.ID).Order("language ASC").Find(&translations)
+
+	languages := []string{original}
+	available := make(map[string]models.PostTranslation, len(translations))
+	availableSet := make(map[string]bool, len(translations))
+	var translatedAt time.Time
+	for _, t := range translations {
+		languages = append(languages, t.Language)
+		available[t.Language] = t
+		availableSet[t.Language] = true
+		if t.UpdatedAt.After(translatedAt) {
+			translatedAt = t.UpdatedAt
+		}
+	}
+
+	requested, wantOriginal := requestedLanguages(c)
+	if wantOriginal {
+		return original, languages, translatedAt
+	}
+
+	lang := pickLanguage(requested, original, availableSet)
+	if translation, ok := available[lang]; ok && lang != original {
+		post.Title = translation.Title
+		post.Paragraphs = decodeTranslationParagraphs(translation)
+		post.PreviewText = translation.PreviewText
+		post.WordCount = translation.WordCount
+		post.ReadingTimeMinutes = translation.ReadingTimeMinutes
+	}
+	return lang, languages, translatedAt
+}
+
+// localizedTitles - заголовки и отрывки постов ленты на языке читателя; посты без подходящего перевода в результат не попадают
+func localizedTitles(db *gorm.DB, c *gin.Context, posts []models.Post) map[uint]models.PostTranslation {
+	result := make(map[uint]models.PostTranslation)
+	requested, wantOriginal := requestedLanguages(c)
+	if wantOriginal || len(requested) == 0 || len(posts) == 0 {
+		return result
+	}
+
+	postIDs := make([]uint, 0, len(posts))
+	for _, p := range posts {
+		postIDs = append(postIDs, p.ID)
+	}
+
+	var translations []models.PostTranslation
+	db.Select("post_id, language, title, preview_text, word_count, reading_time_minutes").
+		Where("post_id IN ? AND language IN ?", postIDs, requested).
+		Find(&translations)
+
+	byPost := make(map[uint]map[string]models.PostTranslation)
+	for _, t := range translations {
+		if byPost[t.PostID] == nil {
+			byPost[t.PostID] = make(map[string]models.PostTranslation)
+		}
+		byPost[t.PostID][t.Language] = t
+	}
+
+	for _, p := range posts {
+		variants := byPost[p.ID]
+		if len(variants) == 0 {
+			continue
+		}
+		available := make(map[string]bool, len(variants))
+		for lang := range variants {
+			available[lang] = true
+		}
+		lang := pickLanguage(requested, postLanguage(p), available)
+		if t, ok := variants[lang]; ok {
+			result[p.ID] = t
+		}
+	}
+	return result
+}
+
+// loadTranslatablePost загружает пост из параметра postID и проверяет язык из параметра lang
+func loadTranslatablePost(c *gin.Context) (models.Post, int, string, bool) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return models.Post{}, 0, "", false
+	}
+
+	postID, err := strconv.ParseUint(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID format"})
+		return models.Post{}, 0, "", false
+	}
+
+	lang := normalizeLanguage(c.Param("lang"))
+	if lang == "" || lang != strings.ToLower(c.Param("lang")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language", "supported": supportedLanguages})
+		return models.Post{}, 0, "", false
+	}
+
+	var post models.Post
+	if err := database.DB.First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return models.Post{}, 0, "", false
+	}
+
+	// Переводы добавляют те же, кто может редактировать пост
+	if !canEditPost(database.DB, post, int(userID)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errEditForbidden.Error()})
+		return models.Post{}, 0, "", false
+	}
+
+	if lang == postLanguage(post) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errTranslationIsOriginal.Error()})
+		return models.Post{}, 0, "", false
+	}
+
+	return post, int(userID), lang, true
+}
+
+// GetPostTranslations - оригинал и все переводы поста
+func GetPostTranslations(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID format"})
+		return
+	}
+
+	var post models.Post
+	if err := database.DB.
+		Preload("Paragraphs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("paragraphs.order ASC")
+		}).
+		First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	if post.Status != models.PostStatusPublished {
+		viewerID, _ := getUserIDFromContext(c)
+		if !isPostMember(database.DB, post, int(viewerID)) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return
+		}
+	}
+
+	var translations []models.PostTranslation
+	if err := database.DB.Where("post_id = ?", post.ID).Order("language ASC").Find(&translations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch translations"})
+		return
+	}
+
+	response := make([]TranslationResponse, 0, len(translations)+1)
+	response = append(response, TranslationResponse{
+		Language:   postLanguage(post),
+		Title:      post.Title,
+		Paragraphs: post.Paragraphs,
+		IsOriginal: true,
+	})
+	for i := range translations {
+		t := translations[i]
+		response = append(response, TranslationResponse{
+			Language:   t.Language,
+			Title:      t.Title,
+			Paragraphs: decodeTranslationParagraphs(t),
+			UpdatedBy:  t.UpdatedBy,
+			UpdatedAt:  &translations[i].UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"original_language": postLanguage(post),
+		"translations":      response,
+	})
+}
+
+// UpsertPostTranslation - добавление или обновление перевода поста на язык :lang
+func UpsertPostTranslation(c *gin.Context) {
+	post, userID, lang, ok := loadTranslatablePost(c)
+	if !ok {
+		return
+	}
+
+	var input TranslationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+		return
+	}
+	if utf8.RuneCountInString(input.Title) > maxTranslationTitleLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Title must be at most %d characters", maxTranslationTitleLength)})
+		return
+	}
+	if input.Paragraphs == nil {
+		input.Paragraphs = []models.Paragraph{}
+	}
+
+	if err := normalizeBlocks(input.Paragraphs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Перевод может ссылаться только на остановки оригинала
+	var stopCount int64
+	database.DB.Model(&models.TripStop{}).Where("post_id = ?", post.ID).Count(&stopCount)
+	if err := validateStopReferences(int(stopCount), input.Paragraphs, nil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var translation models.PostTranslation
+	created := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPhotoBlockUrls(tx, post.ID, input.Paragraphs); err != nil {
+			return err
+		}
+
+		for i := range input.Paragraphs {
+			input.Paragraphs[i].ID = 0
+			input.Paragraphs[i].PostID = post.ID
+		}
+		paragraphsJSON, err := json.Marshal(input.Paragraphs)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("post_id = ? AND language = ?", post.ID, lang).First(&translation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			created = true
+			translation = models.PostTranslation{PostID: post.ID, Language: lang}
+		} else if err != nil {
+			return err
+		}
+
+		translation.Title = input.Title
+		translation.Paragraphs = string(paragraphsJSON)
+		translation.UpdatedBy = userID
+		translation.UpdatedAt = time.Now()
+		if err := setTranslationPreview(tx, &translation, input.Paragraphs); err != nil {
+			return err
+		}
+		if err := tx.Save(&translation).Error; err != nil {
+			return err
+		}
+		return updatePostSearchDocument(tx, post.ID)
+	})
+
+	if err != nil {
+		var blockErr *blockError
+		if errors.As(err, &blockErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save translation", "details": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, TranslationResponse{
+		Language:   translation.Language,
+		Title:      translation.Title,
+		Paragraphs: input.Paragraphs,
+		UpdatedBy:  translation.UpdatedBy,
+		UpdatedAt:  &translation.UpdatedAt,
+	})
+}
+
+// DeletePostTranslation - удаление перевода; оригинал удалить нельзя
+func DeletePostTranslation(c *gin.Context) {
+	post, _, lang, ok := loadTranslatablePost(c)
+	if !ok {
+		return
+	}
+
+	var deleted int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND language = ?", post.ID, lang).Delete(&models.PostTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if deleted = result.RowsAffected; deleted == 0 {
+			return nil
+		}
+		return updatePostSearchDocument(tx, post.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete translation"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Translation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Translation deleted"})
+}
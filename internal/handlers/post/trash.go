@@ -0,0 +1,302 @@
+package post
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TrashedPostResponse - пост в корзине
+type TrashedPostResponse struct {
+	ID             uint              `json:"id"`
+	Title          string            `json:"title"`
+	SettlementName string            `json:"settlement_name"`
+	Status         models.PostStatus `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	DeletedAt      time.Time         `json:"deleted_at"`
+	PurgeAt        time.Time         `json:"purge_at"`
+	CoverUrl       string            `json:"cover_url"`
+	LikesCount     int               `json:"likes_count"`
+	CommentsCount  int64             `json:"comments_count"`
+}
+
+// trashRetention - сколько пост хранится в корзине до окончательного удаления
+func trashRetention() time.Duration {
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days > 0 {
+			return time.Duration(days) * 24 * time.Hour
+		}
+	}
+	return 30 * 24 * time.Hour
+}
+
+// findTrashedPost - пост автора из корзины по параметру postID
+func findTrashedPost(c *gin.Context) (models.Post, bool) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return models.Post{}, false
+	}
+
+	postID, err := strconv.ParseUint(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID format"})
+		return models.Post{}, false
+	}
+
+	var post models.Post
+	if err := database.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", postID, userID).
+		First(&post).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found in trash"})
+		return models.Post{}, false
+	}
+	return post, true
+}
+
+// GetTrash - посты текущего пользователя в корзине, недавно удалённые первыми
+func GetTrash(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var posts []models.Post
+	if err := database.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\" ASC")
+		}).
+		Order("deleted_at DESC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	retention := trashRetention()
+	response := make([]TrashedPostResponse, 0, len(posts))
+	for _, p := range posts {
+		cover := ""
+		if photos := feedPhotos(p.Photos); len(photos) > 0 {
+			cover = photos[0].Url
+		}
+
+		var commentsCount int64
+		database.DB.Model(&models.Comment{}).Where("post_id = ?", p.ID).Count(&commentsCount)
+
+		response = append(response, TrashedPostResponse{
+			ID:             p.ID,
+			Title:          p.Title,
+			SettlementName: p.SettlementName,
+			Status:         p.Status,
+			CreatedAt:      p.CreatedAt,
+			DeletedAt:      p.DeletedAt.Time,
+			PurgeAt:        p.DeletedAt.Time.Add(retention),
+			CoverUrl:       cover,
+			LikesCount:     p.LikesCount,
+			CommentsCount:  commentsCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":          response,
+		"retention_days": int(retention.Hours() / 24),
+	})
+}
+
+// RestorePost - возврат поста из корзины со всеми лайками, комментариями и соавторами
+func RestorePost(c *gin.Context) {
+	post, ok := findTrashedPost(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Unscoped().Model(&models.Post{}).
+		Where("id = ?", post.ID).
+		Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore post"})
+		return
+	}
+
+	// Опубликованный пост снова появляется в лентах
+	if post.Status == models.PostStatusPublished {
+		database.DB.Where("post_id = ?", post.ID).Order("\"order\" ASC").Find(&post.Photos)
+		broadcastNewPost(post, loadPostTags(post.ID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post restored",
+		"id":      post.ID,
+		"slug":    post.Slug,
+	})
+}
+
+// PurgeTrashedPost - окончательное удаление поста из корзины, не дожидаясь конца срока хранения
+func PurgeTrashedPost(c *gin.Context) {
+	post, ok := findTrashedPost(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return purgePost(tx, post)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Delete failed", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post and associated data deleted permanently"})
+}
+
+// RunTrashPurger периодически окончательно удаляет посты, пролежавшие в корзине дольше срока хранения
+func RunTrashPurger(interval time.Duration) {
+	purgeExpiredTrash()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purgeExpiredTrash()
+	}
+}
+
+func purgeExpiredTrash() {
+	var expired []models.Post
+	if err := database.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-trashRetention())).
+		Find(&expired).Error; err != nil {
+		log.Printf("Ошибка выборки постов из корзины: %v", err)
+		return
+	}
+
+	for _, post := range expired {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// Пост могли восстановить, пока шла очистка
+			var current models.Post
+			if err := tx.Unscoped().
+				Where("id = ? AND deleted_at IS NOT NULL", post.ID).
+				First(&current).Error; err != nil {
+				return err
+			}
+			return purgePost(tx, current)
+		})
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Ошибка очистки поста %d из корзины: %v", post.ID, err)
+			continue
+		}
+		if err == nil {
+			log.Printf("🗑️ Пост %d удалён из корзины окончательно", post.ID)
+		}
+	}
+}
+
+// purgePost окончательно удаляет пост из корзины вместе со всеми связанными данными
+func purgePost(tx *gorm.DB, post models.Post) error {
+	// Удаляем связанные данные
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Like{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Favourite{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Complaint{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Paragraph{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostPhoto{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostRevision{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.TripStop{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+		return err
+	}
+
+	// Отзыв о месте остаётся, пропадает только ссылка на пост
+	if err := tx.Model(&models.Review{}).Where("post_id = ?", post.ID).Update("post_id", nil).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTranslation{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostSlugHistory{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostView{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostDailyStat{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostFingerprint{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostFingerprintBand{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.DuplicateFlag{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostSearchDocument{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+
+	// ========== НОВЫЙ КОД ДЛЯ КОЛЛАБОРАЦИЙ ==========
+	// Удаляем всех соавторов поста
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostCollaborator{}).Error; err != nil {
+		log.Printf("Ошибка при удалении соавторов: %v", err)
+		return err
+	}
+
+	// Удаляем все приглашения, связанные с постом
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.CollaborationInvite{}).Error; err != nil {
+		log.Printf("Ошибка при удалении приглашений: %v", err)
+		return err
+	}
+	// ==============================================
+
+	// Удаляем пост
+	return tx.Unscoped().Delete(&post).Error
+}
@@ -0,0 +1,141 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"padaroja/internal/domain/models"
+	"padaroja/utils"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// TripStopRequest - остановка поездки во входящем запросе; порядок задаётся позицией в массиве
+type TripStopRequest struct {
+	SettlementID   uint   `json:"settlement_id" binding:"required"`
+	SettlementName string `json:"settlement_name"`
+}
+
+// tripStopError - ошибка в остановках поездки или ссылках на них, возвращается клиенту как 400
+type tripStopError struct {
+	Msg string
+}
+
+func (e *tripStopError) Error() string { return e.Msg }
+
+// buildTripStops проверяет остановки так же, как основной населённый пункт, и считает длину маршрута
+func buildTripStops(tx *gorm.DB, input []TripStopRequest) ([]models.TripStop, float64, error) {
+	stops := make([]models.TripStop, 0, len(input))
+	points := make([][2]float64, 0, len(input))
+
+	for i, stop := range input {
+		if stop.SettlementID == 0 {
+			return nil, 0, &tripStopError{fmt.Sprintf("stop %d: settlement_id is required", i+1)}
+		}
+
+		var settlement models.Settlement
+		if err := tx.Select("geonameid, latitude, longitude").
+			First(&settlement, "geonameid = ?", stop.SettlementID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, 0, &tripStopError{fmt.Sprintf("stop %d: settlement with ID %d not found", i+1, stop.SettlementID)}
+			}
+			return nil, 0, err
+		}
+
+		correctedName, err := validateSettlement(tx, stop.SettlementID, utils.CleanSettlementName(stop.SettlementName))
+		if err != nil {
+			return nil, 0, fmt.Errorf("stop %d: %w", i+1, err)
+		}
+
+		stops = append(stops, models.TripStop{
+			Order:          i + 1,
+			SettlementID:   stop.SettlementID,
+			SettlementName: correctedName,
+		})
+		points = append(points, [2]float64{settlement.Latitude, settlement.Longitude})
+	}
+
+	return stops, utils.RouteDistanceKm(points), nil
+}
+
+// validateStopReferences проверяет, что параграфы и фото ссылаются на существующие остановки
+func validateStopReferences(stopCount int, paragraphs []models.Paragraph, photos []models.PostPhoto) error {
+	for _, p := range paragraphs {
+		if p.StopOrder != nil && (*p.StopOrder < 1 || *p.StopOrder > stopCount) {
+			return &tripStopError{fmt.Sprintf("paragraph %d refers to unknown stop %d", p.Order, *p.StopOrder)}
+		}
+	}
+	for _, p := range photos {
+		if p.StopOrder != nil && (*p.StopOrder < 1 || *p.StopOrder > stopCount) {
+			return &tripStopError{fmt.Sprintf("photo %d refers to unknown stop %d", p.Order, *p.StopOrder)}
+		}
+	}
+	return nil
+}
+
+// replaceTripStops заменяет остановки поездки и сохраняет длину маршрута.
+// Параграфы и фото, привязанные к исчезнувшим остановкам, остаются без привязки.
+func replaceTripStops(tx *gorm.DB, postID uint, stops []models.TripStop, distanceKm float64) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&models.TripStop{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Paragraph{}).
+		Where("post_id = ? AND stop_order > ?", postID, len(stops)).
+		Update("stop_order", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.PostPhoto{}).
+		Where("post_id = ? AND stop_order > ?", postID, len(stops)).
+		Update("stop_order", nil).Error; err != nil {
+		return err
+	}
+	if len(stops) > 0 {
+		for i := range stops {
+			stops[i].ID = 0
+			stops[i].PostID = postID
+		}
+		if err := tx.Create(&stops).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&models.Post{}).Where("id = ?", postID).Update("route_distance_km", distanceKm).Error
+}
+
+// routeDistanceForStops пересчитывает длину маршрута по уже сохранённым остановкам
+func routeDistanceForStops(tx *gorm.DB, stops []models.TripStop) (float64, error) {
+	points := make([][2]float64, 0, len(stops))
+	for _, stop := range stops {
+		var settlement models.Settlement
+		if err := tx.Select("geonameid, latitude, longitude").
+			First(&settlement, "geonameid = ?", stop.SettlementID).Error; err != nil {
+			return 0, err
+		}
+		points = append(points, [2]float64{settlement.Latitude, settlement.Longitude})
+	}
+	return utils.RouteDistanceKm(points), nil
+}
+
+// stopNames возвращает названия остановок по порядку (используется в diff версий)
+func stopNames(stops []models.TripStop) []string {
+	names := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		names = append(names, stop.SettlementName)
+	}
+	return names
+}
+
+func stopIDs(stops []models.TripStop) []string {
+	ids := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		ids = append(ids, strconv.FormatUint(uint64(stop.SettlementID), 10))
+	}
+	return ids
+}
+
+func requestedStopIDs(stops []TripStopRequest) []string {
+	ids := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		ids = append(ids, strconv.FormatUint(uint64(stop.SettlementID), 10))
+	}
+	return ids
+}
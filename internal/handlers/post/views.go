@@ -0,0 +1,205 @@
+package post
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// Очередь просмотров между GetPost и агрегатором; при переполнении просмотры теряются, а не тормозят ответ
+const viewQueueSize = 10000
+
+type viewEvent struct {
+	PostID    uint
+	ViewerKey string
+	At        time.Time
+}
+
+var (
+	viewEvents = make(chan viewEvent, viewQueueSize)
+
+	// Соль отпечатка анонимного читателя; без VIEW_FINGERPRINT_SALT генерируется при запуске
+	viewFingerprintSalt = os.Getenv("VIEW_FINGERPRINT_SALT")
+)
+
+func init() {
+	if viewFingerprintSalt == "" {
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		viewFingerprintSalt = hex.EncodeToString(buf)
+	}
+}
+
+// viewDedupWindow - окно, в котором повторные просмотры одного читателя считаются одним
+func viewDedupWindow() time.Duration {
+	if v := os.Getenv("VIEW_DEDUP_WINDOW_HOURS"); v != "" {
+		if hours, err := strconv.Atoi(v); err == nil && hours > 0 {
+			return time.Duration(hours) * time.Hour
+		}
+	}
+	return 6 * time.Hour
+}
+
+// viewRetention - сколько хранить отдельные просмотры; дневные счётчики хранятся всегда
+func viewRetention() time.Duration {
+	if v := os.Getenv("VIEW_RETENTION_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days > 0 {
+			return time.Duration(days) * 24 * time.Hour
+		}
+	}
+	return 90 * 24 * time.Hour
+}
+
+// viewerKey - пользователь по ID, аноним по отпечатку IP и браузера (сами IP не сохраняются)
+func viewerKey(c *gin.Context) string {
+	if userID, ok := getUserIDFromContext(c); ok {
+		return fmt.Sprintf("u:%d", userID)
+	}
+	sum := sha256.Sum256([]byte(viewFingerprintSalt + "|" + c.ClientIP() + "|" + c.GetHeader("User-Agent")))
+	return "a:" + hex.EncodeToString(sum[:16])
+}
+
+// recordView ставит просмотр поста в очередь агрегатора; автор свои просмотры не накручивает
+func recordView(c *gin.Context, post models.Post) {
+	if post.Status != models.PostStatusPublished {
+		return
+	}
+	if userID, ok := getUserIDFromContext(c); ok && int(userID) == post.UserID {
+		return
+	}
+
+	select {
+	case viewEvents <- viewEvent{PostID: post.ID, ViewerKey: viewerKey(c), At: time.Now().UTC()}:
+	default:
+	}
+}
+
+// RunViewAggregator периодически записывает накопленные просмотры и пересчитывает дневные счётчики
+func RunViewAggregator(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pending []viewEvent
+	lastPrune := time.Now()
+
+	for {
+		select {
+		case event := <-viewEvents:
+			pending = append(pending, event)
+		case <-ticker.C:
+			if len(pending) > 0 {
+				if err := flushViews(pending); err != nil {
+					log.Printf("Ошибка записи просмотров: %v", err)
+				}
+				pending = nil
+			}
+			if time.Since(lastPrune) > time.Hour {
+				pruneViews()
+				lastPrune = time.Now()
+			}
+		}
+	}
+}
+
+func flushViews(events []viewEvent) error {
+	window := viewDedupWindow()
+
+	// Повторы внутри пачки отбрасываем сразу, между пачками - уникальным индексом
+	type viewKey struct {
+		postID      uint
+		viewerKey   string
+		windowStart time.Time
+	}
+	seen := make(map[viewKey]bool, len(events))
+	rows := make([]models.PostView, 0, len(events))
+	postIDs := make([]uint, 0)
+	days := make([]time.Time, 0)
+	seenPosts := make(map[uint]bool)
+	seenDays := make(map[time.Time]bool)
+
+	for _, e := range events {
+		k := viewKey{e.PostID, e.ViewerKey, e.At.Truncate(window)}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		day := time.Date(e.At.Year(), e.At.Month(), e.At.Day(), 0, 0, 0, 0, time.UTC)
+		rows = append(rows, models.PostView{
+			PostID:      e.PostID,
+			ViewerKey:   e.ViewerKey,
+			WindowStart: k.windowStart,
+			Day:         day,
+			CreatedAt:   e.At,
+		})
+		if !seenPosts[e.PostID] {
+			seenPosts[e.PostID] = true
+			postIDs = append(postIDs, e.PostID)
+		}
+		if !seenDays[day] {
+			seenDays[day] = true
+			days = append(days, day)
+		}
+	}
+
+	// Пост могли удалить, пока просмотр ждал в очереди
+	var existing []uint
+	if err := database.DB.Model(&models.Post{}).Where("id IN ?", postIDs).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+	exists := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+	filtered := rows[:0]
+	for _, row := range rows {
+		if exists[row.PostID] {
+			filtered = append(filtered, row)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&filtered, 500).Error; err != nil {
+		return err
+	}
+
+	// Счётчики пересчитываются из просмотров целиком, поэтому повторный пересчёт безопасен
+	if err := database.DB.Exec(`
+		INSERT INTO post_daily_stats (post_id, day, views, unique_readers)
+		SELECT post_id, day, COUNT(*), COUNT(DISTINCT viewer_key)
+		FROM post_views
+		WHERE post_id IN ? AND day IN ?
+		GROUP BY post_id, day
+		ON CONFLICT (post_id, day) DO UPDATE
+		SET views = EXCLUDED.views, unique_readers = EXCLUDED.unique_readers
+	`, existing, days).Error; err != nil {
+		return err
+	}
+
+	return database.DB.Exec(`
+		UPDATE posts SET views_count = (
+			SELECT COALESCE(SUM(views), 0) FROM post_daily_stats WHERE post_daily_stats.post_id = posts.id
+		)
+		WHERE id IN ?
+	`, existing).Error
+}
+
+// pruneViews удаляет старые отдельные просмотры; дневные счётчики за эти дни не пересчитываются
+func pruneViews() {
+	cutoff := time.Now().Add(-viewRetention())
+	if err := database.DB.Where("created_at < ?", cutoff).Delete(&models.PostView{}).Error; err != nil {
+		log.Printf("Ошибка очистки старых просмотров: %v", err)
+	}
+}
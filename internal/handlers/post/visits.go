@@ -0,0 +1,41 @@
+package post
+
+import (
+	"fmt"
+	"padaroja/utils"
+	"time"
+)
+
+// visitDateError - некорректные даты поездки при редактировании поста
+type visitDateError struct {
+	err error
+}
+
+func (e *visitDateError) Error() string { return e.err.Error() }
+
+// formatDate - дата в формате запроса; nil - пустая строка
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(utils.DateLayout)
+}
+
+// parseVisitDates проверяет даты поездки из запроса; конец без начала и конец раньше начала - ошибка
+func parseVisitDates(from, to string) (*time.Time, *time.Time, error) {
+	visitedFrom, err := utils.ParseDate(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	visitedTo, err := utils.ParseDate(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if visitedTo != nil && visitedFrom == nil {
+		return nil, nil, fmt.Errorf("visited_from is required when visited_to is set")
+	}
+	if visitedFrom != nil && visitedTo != nil && visitedTo.Before(*visitedFrom) {
+		return nil, nil, fmt.Errorf("visited_to must not be before visited_from")
+	}
+	return visitedFrom, visitedTo, nil
+}
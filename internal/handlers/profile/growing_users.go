@@ -38,6 +38,7 @@ func SearchUsers(c *gin.Context) {
                 COALESCE((
                     SELECT COUNT(*) FROM posts p 
                     WHERE p.user_id = u.id 
+                    AND p.deleted_at IS NULL
                     AND p.created_at > $1
                 ), 0) +
                 COALESCE((
@@ -58,6 +59,7 @@ func SearchUsers(c *gin.Context) {
             OR COALESCE((
                 SELECT COUNT(*) FROM posts p 
                 WHERE p.user_id = u.id 
+                AND p.deleted_at IS NULL
                 AND p.created_at > $1
             ), 0) > 0
             OR COALESCE((
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"padaroja/internal/domain/models"
+	"padaroja/internal/handlers/collection"
 	database "padaroja/internal/storage/postgres"
 	"strconv"
 
@@ -152,11 +153,12 @@ func GetUserProfileByID(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":        user.ID,
-		"username":  user.Username,
-		"bio":       user.Bio,
-		"image_url": user.ImageUrl,
-		"role_id":   user.RoleID,
+		"id":          user.ID,
+		"username":    user.Username,
+		"bio":         user.Bio,
+		"image_url":   user.ImageUrl,
+		"role_id":     user.RoleID,
+		"collections": collection.PublicCollectionsSummary(database.DB, user.ID),
 	})
 }
 
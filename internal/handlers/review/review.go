@@ -0,0 +1,344 @@
+package review
+
+import (
+	"errors"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Максимальная длина текста отзыва, символов
+const maxReviewTextLength = 1000
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Text   string `json:"text"`
+	PostID *uint  `json:"post_id"`
+}
+
+// RecalculateSettlementRating пересчитывает число одобренных отзывов и среднюю оценку населённого пункта
+func RecalculateSettlementRating(db *gorm.DB, settlementID uint) error {
+	return db.Exec(`
+		UPDATE settlements SET
+			reviews_count = stats.reviews_count,
+			average_rating = stats.average_rating
+		FROM (
+			SELECT COUNT(*) AS reviews_count, COALESCE(ROUND(AVG(rating)::numeric, 2), 0) AS average_rating
+			FROM reviews
+			WHERE settlement_id = ? AND is_approved = true
+		) AS stats
+		WHERE settlements.geonameid = ?
+	`, settlementID, settlementID).Error
+}
+
+func getUserID(c *gin.Context) (int, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := userIDValue.(uint)
+	return int(userID), ok
+}
+
+func parseSettlementID(c *gin.Context) (uint, bool) {
+	settlementID, err := strconv.ParseUint(c.Param("settlementID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settlement ID"})
+		return 0, false
+	}
+	return uint(settlementID), true
+}
+
+// settlementName - русское название населённого пункта, если оно есть
+func settlementName(s models.Settlement) string {
+	if name := utils.ExtractRussianName(s.Alternatenames); name != "" {
+		return name
+	}
+	return s.Name
+}
+
+// GetSettlementReviews - одобренные отзывы о населённом пункте, сводка оценок и свой отзыв читателя.
+// Параметры: sort (new - по умолчанию, rating_high, rating_low), page, limit.
+func GetSettlementReviews(c *gin.Context) {
+	settlementID, ok := parseSettlementID(c)
+	if !ok {
+		return
+	}
+
+	var settlement models.Settlement
+	if err := database.DB.First(&settlement, "geonameid = ?", settlementID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Settlement not found"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	order := "created_at DESC"
+	switch c.Query("sort") {
+	case "rating_high":
+		order = "rating DESC, created_at DESC"
+	case "rating_low":
+		order = "rating ASC, created_at DESC"
+	}
+
+	var reviews []models.Review
+	if err := database.DB.
+		Where("settlement_id = ? AND is_approved = ?", settlementID, true).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, username, image_url")
+		}).
+		Order(order).
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	// Распределение оценок: сколько отзывов с 1, 2, ... 5 звёздами
+	var distribution []struct {
+		Rating int
+		Count  int
+	}
+	database.DB.Model(&models.Review{}).
+		Select("rating, COUNT(*) AS count").
+		Where("settlement_id = ? AND is_approved = ?", settlementID, true).
+		Group("rating").
+		Scan(&distribution)
+	stars := gin.H{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
+	for _, d := range distribution {
+		stars[strconv.Itoa(d.Rating)] = d.Count
+	}
+
+	response := gin.H{
+		"settlement": gin.H{
+			"id":             settlement.Geonameid,
+			"name":           settlementName(settlement),
+			"reviews_count":  settlement.ReviewsCount,
+			"average_rating": settlement.AverageRating,
+			"distribution":   stars,
+		},
+		"reviews":  reviews,
+		"page":     page,
+		"limit":    limit,
+		"has_more": (page-1)*limit+len(reviews) < settlement.ReviewsCount,
+	}
+
+	// Свой отзыв показываем, даже если его скрыл модератор
+	if userID, ok := getUserID(c); ok {
+		var own models.Review
+		if err := database.DB.Where("settlement_id = ? AND user_id = ?", settlementID, userID).First(&own).Error; err == nil {
+			response["my_review"] = own
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpsertReview - создать или изменить свой отзыв о населённом пункте
+func UpsertReview(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	settlementID, ok := parseSettlementID(c)
+	if !ok {
+		return
+	}
+
+	var input ReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: rating must be between 1 and 5"})
+		return
+	}
+	input.Text = strings.TrimSpace(input.Text)
+	if utf8.RuneCountInString(input.Text) > maxReviewTextLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Review text is too long"})
+		return
+	}
+
+	var settlement models.Settlement
+	if err := database.DB.Select("geonameid").First(&settlement, "geonameid = ?", settlementID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Settlement not found"})
+		return
+	}
+
+	// Связать с отзывом можно только свой пост об этом месте (основной пункт или остановка поездки)
+	if input.PostID != nil {
+		var count int64
+		database.DB.Model(&models.Post{}).
+			Where("id = ? AND user_id = ?", *input.PostID, userID).
+			Where("settlement_id = ? OR id IN (SELECT post_id FROM trip_stops WHERE settlement_id = ?)", settlementID, settlementID).
+			Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Post must be your own post about this settlement"})
+			return
+		}
+	}
+
+	var review models.Review
+	created := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("settlement_id = ? AND user_id = ?", settlementID, userID).First(&review).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			created = true
+			review = models.Review{SettlementID: settlementID, UserID: userID, IsApproved: true}
+		} else if err != nil {
+			return err
+		}
+
+		review.Rating = input.Rating
+		review.Text = input.Text
+		review.PostID = input.PostID
+		review.UpdatedAt = time.Now()
+		if err := tx.Save(&review).Error; err != nil {
+			return err
+		}
+		return RecalculateSettlementRating(tx, settlementID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	database.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, username, image_url")
+	}).First(&review, review.ID)
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"message": "Review saved successfully",
+		"review":  review,
+	})
+}
+
+// DeleteReview - удалить свой отзыв о населённом пункте
+func DeleteReview(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	settlementID, ok := parseSettlementID(c)
+	if !ok {
+		return
+	}
+
+	var review models.Review
+	if err := database.DB.Where("settlement_id = ? AND user_id = ?", settlementID, userID).First(&review).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", review.ID).Delete(&models.Complaint{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return RecalculateSettlementRating(tx, settlementID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
+// GetTopSettlements - лучшие по оценкам места региона.
+// Регион задаётся кодом области (region) и района (district) или населённым пунктом (settlement_id) - тогда берётся его область.
+// sort: score (по умолчанию; средняя оценка с поправкой на малое число отзывов), rating, reviews.
+func GetTopSettlements(c *gin.Context) {
+	limit := 20
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 100 {
+		limit = v
+	}
+	minReviews := 1
+	if v, err := strconv.Atoi(c.Query("min_reviews")); err == nil && v > 0 {
+		minReviews = v
+	}
+
+	query := database.DB.Model(&models.Settlement{}).Where("reviews_count >= ?", minReviews)
+
+	region, district := c.Query("region"), c.Query("district")
+	if v := c.Query("settlement_id"); v != "" {
+		var origin models.Settlement
+		if err := database.DB.Select("geonameid, admin1_code").First(&origin, "geonameid = ?", v).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Settlement not found"})
+			return
+		}
+		region = origin.Admin1Code
+	}
+	if region != "" {
+		query = query.Where("admin1_code = ?", region)
+	}
+	if district != "" {
+		query = query.Where("admin2_code = ?", district)
+	}
+
+	// Средняя оценка с поправкой: к отзывам места добавляются несколько "средних по сайту",
+	// чтобы одна пятёрка не обгоняла сотню оценок 4.8
+	const priorWeight = 5
+	var globalAverage float64
+	database.DB.Model(&models.Review{}).Where("is_approved = ?", true).
+		Select("COALESCE(AVG(rating), 0)").Scan(&globalAverage)
+
+	switch c.Query("sort") {
+	case "rating":
+		query = query.Order("average_rating DESC, reviews_count DESC")
+	case "reviews":
+		query = query.Order("reviews_count DESC, average_rating DESC")
+	default:
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(average_rating * reviews_count + ? * ?) / (reviews_count + ?) DESC",
+			Vars:               []interface{}{globalAverage, priorWeight, priorWeight},
+			WithoutParentheses: true,
+		}})
+	}
+
+	var settlements []models.Settlement
+	if err := query.Limit(limit).Find(&settlements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settlements"})
+		return
+	}
+
+	results := make([]gin.H, 0, len(settlements))
+	for _, s := range settlements {
+		score := (s.AverageRating*float64(s.ReviewsCount) + globalAverage*priorWeight) / float64(s.ReviewsCount+priorWeight)
+		results = append(results, gin.H{
+			"id":             s.Geonameid,
+			"name":           settlementName(s),
+			"latitude":       s.Latitude,
+			"longitude":      s.Longitude,
+			"region":         s.Admin1Code,
+			"district":       s.Admin2Code,
+			"reviews_count":  s.ReviewsCount,
+			"average_rating": s.AverageRating,
+			"score":          float64(int(score*100+0.5)) / 100,
+		})
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,411 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	"padaroja/internal/handlers/post"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errSynonymIsTag   = errors.New("synonym matches the tag name")
+	errSynonymExists  = errors.New("synonym already exists")
+	errMergeIntoSelf  = errors.New("cannot merge a tag into itself")
+	errNoSourceTags   = errors.New("no tags to merge")
+	errTargetNotFound = errors.New("tag not found")
+)
+
+type SynonymRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type MergeRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required"`
+}
+
+// checkAdminRights - управлять тегами могут только администраторы (role_id = 3)
+func checkAdminRights(c *gin.Context) (uint, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+
+	currentUserID, ok := userIDValue.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		return 0, false
+	}
+
+	var currentUser models.User
+	if err := database.DB.First(&currentUser, currentUserID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user data"})
+		return 0, false
+	}
+
+	if currentUser.RoleID != 3 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Admin rights required."})
+		return 0, false
+	}
+
+	return currentUserID, true
+}
+
+func parseTagID(c *gin.Context) (uint, bool) {
+	tagID, err := strconv.ParseUint(c.Param("tagID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return 0, false
+	}
+	return uint(tagID), true
+}
+
+// mergeTags переносит посты и синонимы исходных тегов на целевой, а названия исходных тегов делает синонимами
+func mergeTags(tx *gorm.DB, target models.Tags, sources []models.Tags, adminID int) error {
+	sourceIDs := make([]uint, 0, len(sources))
+	for _, source := range sources {
+		if source.ID == target.ID {
+			return errMergeIntoSelf
+		}
+		sourceIDs = append(sourceIDs, source.ID)
+	}
+	// Названия тегов входят в поисковый документ, поэтому документы этих постов нужно пересобрать
+	postIDs, err := taggedPostIDs(tx, sourceIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+
+		// Посты, у которых уже есть целевой тег, не должны получить его второй раз
+		if err := tx.Exec(`DELETE FROM post_tags WHERE tag_id = ? AND post_id IN (
+			SELECT post_id FROM post_tags WHERE tag_id = ?
+		)`, source.ID, target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PostTag{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TagSynonym{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Tags{}, source.ID).Error; err != nil {
+			return err
+		}
+
+		name := utils.NormalizeTagName(source.Name)
+		if name == "" || name == utils.NormalizeTagName(target.Name) {
+			continue
+		}
+		synonym := models.TagSynonym{Name: name, TagID: target.ID, CreatedBy: adminID}
+		if err := tx.Where(models.TagSynonym{Name: name}).Assign(models.TagSynonym{TagID: target.ID}).
+			FirstOrCreate(&synonym).Error; err != nil {
+			return err
+		}
+	}
+	return post.UpdatePostSearchDocuments(tx, postIDs)
+}
+
+// taggedPostIDs возвращает посты, отмеченные любым из тегов
+func taggedPostIDs(tx *gorm.DB, tagIDs []uint) ([]uint, error) {
+	var postIDs []uint
+	err := tx.Model(&models.PostTag{}).Where("tag_id IN ?", tagIDs).Distinct().Pluck("post_id", &postIDs).Error
+	return postIDs, err
+}
+
+// GetTagsForAdmin - список тегов с числом постов и синонимами (поиск по ?search=)
+func GetTagsForAdmin(c *gin.Context) {
+	if _, ok := checkAdminRights(c); !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit := 50
+
+	query := database.DB.Table("tags").
+		Select("tags.id, tags.name, COUNT(DISTINCT post_tags.post_id) AS posts_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Group("tags.id, tags.name")
+
+	if search := utils.NormalizeTagName(c.Query("search")); search != "" {
+		pattern := "%" + utils.EscapeLike(search) + "%"
+		query = query.Where(`tags.name LIKE ? ESCAPE '\' OR tags.id IN (SELECT tag_id FROM tag_synonyms WHERE name LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+
+	var tags []TagUsage
+	if err := query.Order("posts_count DESC, tags.name ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	tagIDs := make([]uint, 0, len(tags))
+	for _, t := range tags {
+		tagIDs = append(tagIDs, t.ID)
+	}
+	var synonyms []models.TagSynonym
+	if len(tagIDs) > 0 {
+		database.DB.Where("tag_id IN ?", tagIDs).Order("name ASC").Find(&synonyms)
+	}
+	byTag := make(map[uint][]models.TagSynonym)
+	for _, s := range synonyms {
+		byTag[s.TagID] = append(byTag[s.TagID], s)
+	}
+
+	response := make([]gin.H, 0, len(tags))
+	for _, t := range tags {
+		tagSynonyms := byTag[t.ID]
+		if tagSynonyms == nil {
+			tagSynonyms = []models.TagSynonym{}
+		}
+		response = append(response, gin.H{
+			"id":          t.ID,
+			"name":        t.Name,
+			"posts_count": t.PostsCount,
+			"synonyms":    tagSynonyms,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": response,
+		"page": page,
+	})
+}
+
+// AddTagSynonym - новый синоним тега; если синоним совпадает с существующим тегом, тот сливается с этим
+func AddTagSynonym(c *gin.Context) {
+	adminID, ok := checkAdminRights(c)
+	if !ok {
+		return
+	}
+	tagID, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var input SynonymRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := utils.NormalizeTagName(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Synonym name is required"})
+		return
+	}
+
+	var synonym models.TagSynonym
+	merged := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var target models.Tags
+		if err := tx.First(&target, tagID).Error; err != nil {
+			return errTargetNotFound
+		}
+		if name == target.Name {
+			return errSynonymIsTag
+		}
+
+		var existing models.TagSynonym
+		if err := tx.Where("name = ?", name).First(&existing).Error; err == nil {
+			return errSynonymExists
+		}
+
+		// Синоним уже используется как самостоятельный тег - переносим его посты
+		var duplicate models.Tags
+		if err := tx.Where("name = ?", name).First(&duplicate).Error; err == nil {
+			merged = true
+			if err := mergeTags(tx, target, []models.Tags{duplicate}, int(adminID)); err != nil {
+				return err
+			}
+			return tx.Where("name = ?", name).First(&synonym).Error
+		}
+
+		synonym = models.TagSynonym{Name: name, TagID: target.ID, CreatedBy: int(adminID)}
+		return tx.Create(&synonym).Error
+	})
+
+	switch {
+	case errors.Is(err, errTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errSynonymIsTag):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errSynonymExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add synonym", "details": err.Error()})
+		return
+	}
+
+	log.Printf("🏷️ Синоним %q добавлен к тегу %d (слияние: %v)", name, tagID, merged)
+	c.JSON(http.StatusCreated, gin.H{
+		"synonym": synonym,
+		"merged":  merged,
+	})
+}
+
+// DeleteTagSynonym - удаление синонима; посты, уже получившие основной тег, не меняются
+func DeleteTagSynonym(c *gin.Context) {
+	if _, ok := checkAdminRights(c); !ok {
+		return
+	}
+
+	synonymID, err := strconv.ParseUint(c.Param("synonymID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid synonym ID"})
+		return
+	}
+
+	result := database.DB.Delete(&models.TagSynonym{}, synonymID)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete synonym"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Synonym not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Synonym deleted"})
+}
+
+// MergeTagsIntoTag - слияние тегов source_ids в тег :tagID
+func MergeTagsIntoTag(c *gin.Context) {
+	adminID, ok := checkAdminRights(c)
+	if !ok {
+		return
+	}
+	tagID, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var input MergeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var target models.Tags
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, tagID).Error; err != nil {
+			return errTargetNotFound
+		}
+
+		var sources []models.Tags
+		if err := tx.Where("id IN ?", input.SourceIDs).Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			return errNoSourceTags
+		}
+		return mergeTags(tx, target, sources, int(adminID))
+	})
+
+	switch {
+	case errors.Is(err, errTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errMergeIntoSelf), errors.Is(err, errNoSourceTags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tags", "details": err.Error()})
+		return
+	}
+
+	var postsCount int64
+	database.DB.Model(&models.PostTag{}).Where("tag_id = ?", target.ID).Count(&postsCount)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("Tags merged into %q", target.Name),
+		"tag":         target,
+		"posts_count": postsCount,
+	})
+}
+
+// NormalizeExistingTags сливает теги, которые отличаются только регистром и пробелами ("Замок", "замок "),
+// и приводит названия к каноничному виду. Вызывается при запуске сервера.
+func NormalizeExistingTags() {
+	var tags []models.Tags
+	if err := database.DB.Order("id ASC").Find(&tags).Error; err != nil {
+		log.Printf("Ошибка выборки тегов: %v", err)
+		return
+	}
+
+	groups := make(map[string][]models.Tags)
+	var order []string
+	for _, t := range tags {
+		name := utils.NormalizeTagName(t.Name)
+		if _, ok := groups[name]; !ok {
+			order = append(order, name)
+		}
+		groups[name] = append(groups[name], t)
+	}
+
+	merged := 0
+	for _, name := range order {
+		group := groups[name]
+		if len(group) == 1 && group[0].Name == name {
+			continue
+		}
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			groupIDs := make([]uint, 0, len(group))
+			for _, t := range group {
+				groupIDs = append(groupIDs, t.ID)
+			}
+			postIDs, err := taggedPostIDs(tx, groupIDs)
+			if err != nil {
+				return err
+			}
+
+			// Пустые после нормализации теги просто удаляем
+			if name == "" {
+				for _, t := range group {
+					if err := tx.Where("tag_id = ?", t.ID).Delete(&models.PostTag{}).Error; err != nil {
+						return err
+					}
+					if err := tx.Delete(&models.Tags{}, t.ID).Error; err != nil {
+						return err
+					}
+				}
+				return post.UpdatePostSearchDocuments(tx, postIDs)
+			}
+
+			target := group[0]
+			if err := mergeTags(tx, target, group[1:], 0); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Tags{}).Where("id = ?", target.ID).Update("name", name).Error; err != nil {
+				return err
+			}
+			return post.UpdatePostSearchDocuments(tx, postIDs)
+		})
+		if err != nil {
+			log.Printf("Ошибка нормализации тега %q: %v", name, err)
+			continue
+		}
+		merged += len(group) - 1
+	}
+
+	if merged > 0 {
+		log.Printf("🏷️ Нормализация тегов: слито %d дубликатов", merged)
+	}
+}
@@ -0,0 +1,113 @@
+package tag
+
+import (
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TagUsage - тег с числом опубликованных постов
+type TagUsage struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	PostsCount int64  `json:"posts_count"`
+}
+
+// publishedPostTags - связи тегов с опубликованными и одобренными постами
+func publishedPostTags(db *gorm.DB) *gorm.DB {
+	return db.Table("post_tags").
+		Joins("JOIN posts ON posts.id = post_tags.post_id").
+		Where("posts.status = ? AND posts.is_approved = ? AND posts.deleted_at IS NULL", models.PostStatusPublished, true)
+}
+
+// GetTag - тег по названию или синониму: синонимы и статистика использования
+func GetTag(c *gin.Context) {
+	name := utils.NormalizeTagName(c.Param("name"))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag name is required"})
+		return
+	}
+
+	tag, resolvedFrom, ok := utils.FindTag(database.DB, name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	var synonyms []string
+	database.DB.Model(&models.TagSynonym{}).Where("tag_id = ?", tag.ID).Order("name ASC").Pluck("name", &synonyms)
+	if synonyms == nil {
+		synonyms = []string{}
+	}
+
+	var stats struct {
+		PostsCount   int64
+		AuthorsCount int64
+		LastUsedAt   *time.Time
+	}
+	publishedPostTags(database.DB).
+		Select("COUNT(DISTINCT posts.id) AS posts_count, COUNT(DISTINCT posts.user_id) AS authors_count, MAX(posts.created_at) AS last_used_at").
+		Where("post_tags.tag_id = ?", tag.ID).
+		Scan(&stats)
+
+	monthAgo := time.Now().AddDate(0, 0, -30)
+	var recentCount int64
+	publishedPostTags(database.DB).
+		Where("post_tags.tag_id = ? AND posts.created_at >= ?", tag.ID, monthAgo).
+		Distinct("posts.id").
+		Count(&recentCount)
+
+	response := gin.H{
+		"id":                 tag.ID,
+		"name":               tag.Name,
+		"synonyms":           synonyms,
+		"posts_count":        stats.PostsCount,
+		"authors_count":      stats.AuthorsCount,
+		"posts_last_30_days": recentCount,
+		"last_used_at":       stats.LastUsedAt,
+	}
+	if resolvedFrom != "" {
+		response["resolved_from"] = resolvedFrom
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AutocompleteTags - подсказки тегов по началу названия (или синонима) для редактора поста, популярные первыми
+func AutocompleteTags(c *gin.Context) {
+	prefix := utils.NormalizeTagName(c.Query("q"))
+	if prefix == "" {
+		c.JSON(http.StatusOK, []TagUsage{})
+		return
+	}
+
+	limit := 10
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 50 {
+		limit = v
+	}
+
+	pattern := utils.EscapeLike(prefix) + "%"
+	var results []TagUsage
+	if err := database.DB.Table("tags").
+		Select("tags.id, tags.name, COUNT(DISTINCT posts.id) AS posts_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.status = ? AND posts.is_approved = ? AND posts.deleted_at IS NULL", models.PostStatusPublished, true).
+		Where(`tags.name LIKE ? ESCAPE '\' OR tags.id IN (SELECT tag_id FROM tag_synonyms WHERE name LIKE ? ESCAPE '\')`, pattern, pattern).
+		Group("tags.id, tags.name").
+		Order("posts_count DESC, tags.name ASC").
+		Limit(limit).
+		Scan(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+	if results == nil {
+		results = []TagUsage{}
+	}
+
+	c.JSON(http.StatusOK, results)
+}
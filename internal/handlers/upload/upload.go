@@ -0,0 +1,138 @@
+package upload
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"padaroja/internal/domain/models"
+	database "padaroja/internal/storage/postgres"
+	"padaroja/internal/storage/uploads"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadPhoto - загрузка фото для поста (multipart, поле "file")
+func UploadPhoto(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := int(userIDValue.(uint))
+
+	upload, ok := storeUploadedImage(c, "photos", userID)
+	if !ok {
+		return
+	}
+
+	response := gin.H{
+		"id":               upload.ID,
+		"url":              upload.Url,
+		"width":            upload.Width,
+		"height":           upload.Height,
+		"mime_type":        upload.MimeType,
+		"size":             upload.Size,
+		"thumbnail_small":  upload.ThumbnailSmall,
+		"thumbnail_medium": upload.ThumbnailMedium,
+		"thumbnail_large":  upload.ThumbnailLarge,
+		"has_location":     false,
+	}
+
+	// Координаты съёмки показываем только тому, кто загрузил файл: по ним клиент
+	// запрашивает подсказку населённого пункта (/api/posts/search/settlements/nearby)
+	if upload.UserID == userID && upload.Latitude != nil && upload.Longitude != nil {
+		response["has_location"] = true
+		response["location"] = gin.H{"latitude": *upload.Latitude, "longitude": *upload.Longitude}
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UploadAvatar - загрузка аватара; сразу сохраняется в профиле пользователя
+func UploadAvatar(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := int(userIDValue.(uint))
+
+	upload, ok := storeUploadedImage(c, "avatars", userID)
+	if !ok {
+		return
+	}
+
+	// Аватар показывается мелко - используем маленькую миниатюру
+	imageUrl := upload.ThumbnailSmall
+	if imageUrl == "" {
+		imageUrl = upload.Url
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("image_url", imageUrl).Error; err != nil {
+		log.Printf("Ошибка обновления аватара пользователя %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update avatar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Avatar updated successfully!",
+		"image_url": imageUrl,
+	})
+}
+
+// storeUploadedImage читает файл из запроса и сохраняет его через uploads.Store
+func storeUploadedImage(c *gin.Context, dir string, userID int) (*models.Upload, bool) {
+	// Небольшой запас на служебные части multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, uploads.MaxBytes+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return nil, false
+	}
+	if fileHeader.Size > uploads.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, uploads.MaxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return nil, false
+	}
+
+	upload, err := uploads.Store(database.DB, dir, userID, data)
+	if err != nil {
+		respondUploadError(c, err)
+		return nil, false
+	}
+
+	return upload, true
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, uploads.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+	case errors.Is(err, uploads.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only JPEG, PNG and WebP images are allowed"})
+	case errors.Is(err, uploads.ErrInvalidImage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image"})
+	default:
+		log.Printf("Ошибка обработки изображения: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
+	}
+}
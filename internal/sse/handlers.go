@@ -112,3 +112,45 @@ func (hub *SSEHub) StreamUserPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// StreamNotifications - личный поток уведомлений пользователя; userID берётся из проверенного токена
+func (hub *SSEHub) StreamNotifications(w http.ResponseWriter, r *http.Request, userID int) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	// CORS-заголовки выставляет общий middleware: поток закрытый, отдаём его только FRONTEND_URL
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(SSEClient, 10)
+	reg := registration{UserID: userID, Client: client, Notifications: true}
+	hub.Register <- reg
+
+	initialMsg, _ := json.Marshal(map[string]string{"type": "CONNECTED"})
+	fmt.Fprintf(w, "data: %s\n\n", initialMsg)
+	flusher.Flush()
+
+	notify := r.Context().Done()
+	go func() {
+		<-notify
+		hub.Unregister <- reg
+	}()
+
+	for {
+		select {
+		case msg, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", msg)
+			flusher.Flush()
+		case <-notify:
+			return
+		}
+	}
+}
@@ -13,10 +13,12 @@ var GlobalHub *SSEHub
 type SSEHub struct {
 	AllPosts      map[SSEClient]bool
 	UserPosts     map[int]map[SSEClient]bool
+	Notifications map[int]map[SSEClient]bool // личные потоки уведомлений, только для вошедшего пользователя
 	Register      chan registration
 	Unregister    chan registration
 	BroadcastAll  chan []byte
 	BroadcastUser chan UserMessage
+	NotifyUser    chan UserMessage
 	stopHeartbeat chan bool
 }
 
@@ -24,10 +26,12 @@ func NewHub() *SSEHub {
 	hub := &SSEHub{
 		AllPosts:      make(map[SSEClient]bool),
 		UserPosts:     make(map[int]map[SSEClient]bool),
+		Notifications: make(map[int]map[SSEClient]bool),
 		Register:      make(chan registration),
 		Unregister:    make(chan registration),
 		BroadcastAll:  make(chan []byte),
 		BroadcastUser: make(chan UserMessage),
+		NotifyUser:    make(chan UserMessage),
 		stopHeartbeat: make(chan bool),
 	}
 
@@ -37,13 +41,29 @@ func NewHub() *SSEHub {
 }
 
 func (hub *SSEHub) Run() {
-	// Start heartbeat
-	go hub.heartbeat()
+	// Heartbeat идёт в этом же цикле: карты клиентов меняются только здесь, и их нельзя обходить из другой горутины
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	heartbeat, stop := ticker.C, hub.stopHeartbeat
 
 	for {
 		select {
+		case <-heartbeat:
+			hub.heartbeat()
+
+		case <-stop:
+			ticker.Stop()
+			heartbeat, stop = nil, nil
+
 		case reg := <-hub.Register:
-			if reg.UserID == -1 {
+			if reg.Notifications {
+				if hub.Notifications[reg.UserID] == nil {
+					hub.Notifications[reg.UserID] = make(map[SSEClient]bool)
+				}
+				hub.Notifications[reg.UserID][reg.Client] = true
+				log.Printf("New client connected to user %d notifications. Total for user: %d",
+					reg.UserID, len(hub.Notifications[reg.UserID]))
+			} else if reg.UserID == -1 {
 				hub.AllPosts[reg.Client] = true
 				log.Printf("New client connected to all posts stream. Total: %d", len(hub.AllPosts))
 			} else {
@@ -56,7 +76,15 @@ func (hub *SSEHub) Run() {
 			}
 
 		case reg := <-hub.Unregister:
-			if reg.UserID == -1 {
+			if reg.Notifications {
+				if clients, ok := hub.Notifications[reg.UserID]; ok {
+					delete(clients, reg.Client)
+					if len(clients) == 0 {
+						delete(hub.Notifications, reg.UserID)
+					}
+				}
+				log.Printf("Client disconnected from user %d notifications", reg.UserID)
+			} else if reg.UserID == -1 {
 				delete(hub.AllPosts, reg.Client)
 				log.Printf("Client disconnected from all posts stream. Remaining: %d", len(hub.AllPosts))
 			} else {
@@ -91,46 +119,54 @@ func (hub *SSEHub) Run() {
 					}
 				}
 			}
+
+		case um := <-hub.NotifyUser:
+			for client := range hub.Notifications[um.UserID] {
+				select {
+				case client <- um.Data:
+				default:
+					// Клиент не успевает читать: уведомление сохранено в БД, он увидит его в списке
+				}
+			}
 		}
 	}
 }
 
-// heartbeat sends a ping every 30 seconds to keep connections alive
+// heartbeat sends a ping to every client to keep connections alive; called from Run every 30 seconds
 func (hub *SSEHub) heartbeat() {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	heartbeatMsg, _ := json.Marshal(map[string]string{"type": "HEARTBEAT"})
 
-	for {
+	// Send to all clients
+	for client := range hub.AllPosts {
 		select {
-		case <-ticker.C:
-			heartbeatMsg, _ := json.Marshal(map[string]string{"type": "HEARTBEAT"})
+		case client <- heartbeatMsg:
+		default:
+			// Client is slow, will be cleaned up in main loop
+		}
+	}
 
-			// Send to all clients
-			for client := range hub.AllPosts {
-				select {
-				case client <- heartbeatMsg:
-				default:
-					// Client is slow, will be cleaned up in main loop
-				}
+	// Send to all user streams
+	for _, clients := range hub.UserPosts {
+		for client := range clients {
+			select {
+			case client <- heartbeatMsg:
+			default:
 			}
+		}
+	}
 
-			// Send to all user streams
-			for _, clients := range hub.UserPosts {
-				for client := range clients {
-					select {
-					case client <- heartbeatMsg:
-					default:
-					}
-				}
+	// Send to notification streams
+	for _, clients := range hub.Notifications {
+		for client := range clients {
+			select {
+			case client <- heartbeatMsg:
+			default:
 			}
-
-			log.Printf("Heartbeat sent to %d all-posts clients and %d user streams",
-				len(hub.AllPosts), len(hub.UserPosts))
-
-		case <-hub.stopHeartbeat:
-			return
 		}
 	}
+
+	log.Printf("Heartbeat sent to %d all-posts clients and %d user streams",
+		len(hub.AllPosts), len(hub.UserPosts))
 }
 
 func (hub *SSEHub) Stop() {
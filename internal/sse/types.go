@@ -3,8 +3,9 @@ package sse
 type SSEClient chan []byte
 
 type registration struct {
-	UserID int
-	Client SSEClient
+	UserID        int
+	Client        SSEClient
+	Notifications bool // личный поток уведомлений, а не поток постов
 }
 
 type UserMessage struct {
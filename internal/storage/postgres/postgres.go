@@ -44,12 +44,23 @@ func ConnectDB() {
 
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	err = db.AutoMigrate(
+	if err := Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
+	DB = db
+	log.Println("Успешное подключение к базе данных и миграция")
+}
+
+// Migrate создаёт и обновляет таблицы и индексы схемы
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.Settlement{},
 		&models.Post{},
 		&models.Paragraph{},
 		&models.PostPhoto{},
+		&models.TripStop{},
 		&models.PostTag{},
 		&models.Tags{},
 		&models.Complaint{},
@@ -60,13 +71,33 @@ func ConnectDB() {
 		&models.PostCollaborator{},
 		&models.CollaborationInvite{},
 		&models.ModeratorAssignment{},
+		&models.PostRevision{},
+		&models.Upload{},
+		&models.Collection{},
+		&models.CollectionItem{},
+		&models.CollectionCurator{},
+		&models.PostTranslation{},
+		&models.PostSlugHistory{},
+		&models.TagSynonym{},
+		&models.PostView{},
+		&models.PostDailyStat{},
+		&models.PostFingerprint{},
+		&models.PostFingerprintBand{},
+		&models.DuplicateFlag{},
+		&models.Review{},
+		&models.PostSearchDocument{},
+		&models.SavedSearch{},
+		&models.Notification{},
 	)
 	if err != nil {
-		log.Fatal("Failed to perform GORM AutoMigrate:", err)
+		return fmt.Errorf("failed to perform GORM AutoMigrate: %w", err)
 	}
 
-	DB = db
-	log.Println("Успешное подключение к базе данных и миграция")
+	// Slug уникален среди постов автора; у старых постов он пуст до заполнения при запуске
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_user_slug ON posts (user_id, slug) WHERE slug <> ''").Error; err != nil {
+		return fmt.Errorf("failed to create posts slug index: %w", err)
+	}
+	return nil
 }
 
 func GetDB() *gorm.DB {
@@ -0,0 +1,209 @@
+package uploads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// exifData - теги EXIF, которые нужны при обработке загруженных фото
+type exifData struct {
+	Orientation int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+const (
+	tagOrientation = 0x0112
+	tagGPSIFD      = 0x8825
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+)
+
+var errNoExif = errors.New("no exif data")
+
+// readExif извлекает нужные теги из сегмента APP1 JPEG-файла
+func readExif(data []byte) (*exifData, error) {
+	tiff, err := findExifSegment(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(tiff, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(tiff, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return nil, errNoExif
+	}
+
+	result := &exifData{Orientation: 1}
+
+	ifd0 := int(order.Uint32(tiff[4:8]))
+	for _, entry := range readIFD(tiff, ifd0, order) {
+		switch entry.tag {
+		case tagOrientation:
+			result.Orientation = int(order.Uint16(entry.value[:2]))
+		case tagGPSIFD:
+			result.Latitude, result.Longitude = readGPS(tiff, int(order.Uint32(entry.value)), order)
+		}
+	}
+
+	return result, nil
+}
+
+// readGPS читает координаты из GPS-каталога; nil, если координат нет или они некорректны
+func readGPS(tiff []byte, offset int, order binary.ByteOrder) (*float64, *float64) {
+	var latRef, lonRef string
+	var lat, lon []float64
+
+	for _, entry := range readIFD(tiff, offset, order) {
+		switch entry.tag {
+		case tagGPSLatitudeRef:
+			latRef = string(bytes.TrimRight(entry.valueData, "\x00"))
+		case tagGPSLongitudeRef:
+			lonRef = string(bytes.TrimRight(entry.valueData, "\x00"))
+		case tagGPSLatitude:
+			lat = readRationals(entry, order)
+		case tagGPSLongitude:
+			lon = readRationals(entry, order)
+		}
+	}
+
+	if len(lat) != 3 || len(lon) != 3 {
+		return nil, nil
+	}
+
+	latitude := lat[0] + lat[1]/60 + lat[2]/3600
+	longitude := lon[0] + lon[1]/60 + lon[2]/3600
+	if latRef == "S" {
+		latitude = -latitude
+	}
+	if lonRef == "W" {
+		longitude = -longitude
+	}
+
+	// Нулевые координаты обычно означают, что камера не получила сигнал GPS
+	if latitude > 90 || latitude < -90 || longitude > 180 || longitude < -180 || (latitude == 0 && longitude == 0) {
+		return nil, nil
+	}
+
+	return &latitude, &longitude
+}
+
+// readRationals декодирует значения типа RATIONAL (числитель/знаменатель)
+func readRationals(entry ifdEntry, order binary.ByteOrder) []float64 {
+	if entry.typ != 5 || len(entry.valueData) < int(entry.count)*8 {
+		return nil
+	}
+	values := make([]float64, 0, entry.count)
+	for i := 0; i < int(entry.count); i++ {
+		num := order.Uint32(entry.valueData[i*8 : i*8+4])
+		den := order.Uint32(entry.valueData[i*8+4 : i*8+8])
+		if den == 0 {
+			return nil
+		}
+		values = append(values, float64(num)/float64(den))
+	}
+	return values
+}
+
+// findExifSegment возвращает TIFF-заголовок из сегмента APP1 "Exif\0\0"
+func findExifSegment(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errNoExif
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, errNoExif
+		}
+		marker := data[pos+1]
+		// Начало данных изображения - дальше метаданных нет
+		if marker == 0xDA || marker == 0xD9 {
+			return nil, errNoExif
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil, errNoExif
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			tiff := segment[6:]
+			if len(tiff) < 8 {
+				return nil, errNoExif
+			}
+			return tiff, nil
+		}
+		pos += 2 + length
+	}
+
+	return nil, errNoExif
+}
+
+type ifdEntry struct {
+	tag       uint16
+	typ       uint16
+	count     uint32
+	value     []byte // 4 байта значения или смещения
+	valueData []byte // данные значения, если они не поместились в 4 байта
+}
+
+// readIFD читает записи каталога TIFF по смещению offset
+func readIFD(tiff []byte, offset int, order binary.ByteOrder) []ifdEntry {
+	if offset <= 0 || offset+2 > len(tiff) {
+		return nil
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	entries := make([]ifdEntry, 0, count)
+
+	for i := 0; i < count; i++ {
+		start := offset + 2 + i*12
+		if start+12 > len(tiff) {
+			break
+		}
+		entry := ifdEntry{
+			tag:   order.Uint16(tiff[start : start+2]),
+			typ:   order.Uint16(tiff[start+2 : start+4]),
+			count: order.Uint32(tiff[start+4 : start+8]),
+			value: tiff[start+8 : start+12],
+		}
+
+		size := typeSize(entry.typ) * int(entry.count)
+		if size > 4 {
+			valueOffset := int(order.Uint32(entry.value))
+			if valueOffset >= 0 && valueOffset+size <= len(tiff) {
+				entry.valueData = tiff[valueOffset : valueOffset+size]
+			}
+		} else {
+			entry.valueData = entry.value[:size]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func typeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7: // BYTE, ASCII, SBYTE, UNDEFINED
+		return 1
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	}
+	return 0
+}
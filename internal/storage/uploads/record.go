@@ -0,0 +1,71 @@
+package uploads
+
+import (
+	"errors"
+	"log"
+	"os"
+	"padaroja/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// Store обрабатывает изображение и возвращает запись Upload.
+// Если такой файл уже загружался, возвращается существующая запись без повторной обработки.
+func Store(db *gorm.DB, dir string, userID int, data []byte) (*models.Upload, error) {
+	if _, err := DetectType(data); err != nil {
+		return nil, err
+	}
+
+	hash := Hash(data)
+
+	var existing models.Upload
+	err := db.Where("hash = ?", hash).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	stored, err := SaveImage(dir, data)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := models.Upload{
+		Hash:            stored.Hash,
+		UserID:          userID,
+		MimeType:        stored.MimeType,
+		Size:            stored.Size,
+		Width:           stored.Width,
+		Height:          stored.Height,
+		Url:             stored.Url,
+		ThumbnailSmall:  stored.ThumbnailSmall,
+		ThumbnailMedium: stored.ThumbnailMedium,
+		ThumbnailLarge:  stored.ThumbnailLarge,
+		Latitude:        stored.Latitude,
+		Longitude:       stored.Longitude,
+	}
+	if err := db.Create(&upload).Error; err != nil {
+		// Тот же файл мог быть загружен параллельно - файлы на диске совпадают, берём существующую запись
+		if db.Where("hash = ?", hash).First(&existing).Error == nil {
+			return &existing, nil
+		}
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// RemoveFiles удаляет с диска оригинал и миниатюры загрузки; уже отсутствующие файлы пропускаются
+func RemoveFiles(upload models.Upload) {
+	for _, url := range []string{upload.Url, upload.ThumbnailSmall, upload.ThumbnailMedium, upload.ThumbnailLarge} {
+		localPath, ok := LocalPath(url)
+		if !ok {
+			continue
+		}
+		if err := os.Remove(localPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Ошибка удаления файла %s: %v", localPath, err)
+		}
+	}
+}
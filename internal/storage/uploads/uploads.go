@@ -0,0 +1,289 @@
+package uploads
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Размеры миниатюр по большей стороне
+const (
+	ThumbnailSmall  = 320
+	ThumbnailMedium = 800
+	ThumbnailLarge  = 1600
+)
+
+// Максимальное число пикселей исходного изображения (защита от "бомб" распаковки)
+const maxPixels = 50_000_000
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+var allowedTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "png", // webp перекодируется в png: стандартная библиотека не умеет кодировать webp
+}
+
+var (
+	Root      string
+	URLPrefix string
+	MaxBytes  int64
+)
+
+// Init читает настройки хранилища файлов из переменных окружения
+func Init() error {
+	Root = os.Getenv("UPLOADS_DIR")
+	if Root == "" {
+		Root = "uploads"
+	}
+
+	URLPrefix = os.Getenv("UPLOADS_URL_PREFIX")
+	if URLPrefix == "" {
+		URLPrefix = "/api/uploads"
+	}
+	URLPrefix = strings.TrimRight(URLPrefix, "/")
+
+	MaxBytes = 10 << 20
+	if v := os.Getenv("UPLOAD_MAX_MB"); v != "" {
+		if mb, err := strconv.Atoi(v); err == nil && mb > 0 {
+			MaxBytes = int64(mb) << 20
+		}
+	}
+
+	if err := os.MkdirAll(Root, 0o755); err != nil {
+		return fmt.Errorf("failed to create uploads dir %s: %w", Root, err)
+	}
+
+	log.Printf("Хранилище файлов: %s (URL %s, лимит %d МБ)", Root, URLPrefix, MaxBytes>>20)
+	return nil
+}
+
+// StoredImage - результат обработки загруженного изображения
+type StoredImage struct {
+	Hash            string
+	MimeType        string
+	Size            int64
+	Width           int
+	Height          int
+	Url             string
+	ThumbnailSmall  string
+	ThumbnailMedium string
+	ThumbnailLarge  string
+
+	// Координаты съёмки из EXIF; в сохранённые файлы они не попадают
+	Latitude  *float64
+	Longitude *float64
+}
+
+// Hash возвращает sha256 содержимого файла - ключ для дедупликации
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// DetectType проверяет размер и MIME-тип по содержимому файла
+func DetectType(data []byte) (string, error) {
+	if int64(len(data)) > MaxBytes {
+		return "", ErrTooLarge
+	}
+	mimeType := http.DetectContentType(data)
+	if _, ok := allowedTypes[mimeType]; !ok {
+		return "", ErrUnsupportedType
+	}
+	return mimeType, nil
+}
+
+// SaveImage перекодирует изображение без метаданных EXIF, сохраняет его и миниатюры.
+// Файлы именуются по хэшу содержимого, поэтому повторная загрузка того же файла ничего не дублирует.
+func SaveImage(dir string, data []byte) (*StoredImage, error) {
+	mimeType, err := DetectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// EXIF удаляется вместе с перекодированием, поэтому ориентацию применяем к пикселям заранее,
+	// а координаты возвращаем вызывающему коду
+	var exif *exifData
+	if mimeType == "image/jpeg" {
+		if exif, err = readExif(data); err == nil {
+			img = applyOrientation(img, exif.Orientation)
+		}
+	}
+
+	hash := Hash(data)
+	ext := allowedTypes[mimeType]
+	relDir := path.Join(dir, hash[:2])
+	if err := os.MkdirAll(filepath.Join(Root, filepath.FromSlash(relDir)), 0o755); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	stored := &StoredImage{
+		Hash:     hash,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+	}
+	if exif != nil {
+		stored.Latitude = exif.Latitude
+		stored.Longitude = exif.Longitude
+	}
+
+	stored.Url, err = writeImage(img, path.Join(relDir, hash+"."+ext), ext)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails := []struct {
+		size int
+		dst  *string
+	}{
+		{ThumbnailSmall, &stored.ThumbnailSmall},
+		{ThumbnailMedium, &stored.ThumbnailMedium},
+		{ThumbnailLarge, &stored.ThumbnailLarge},
+	}
+	for _, t := range thumbnails {
+		// Не увеличиваем маленькие изображения - отдаём оригинал
+		if stored.Width <= t.size && stored.Height <= t.size {
+			*t.dst = stored.Url
+			continue
+		}
+		name := fmt.Sprintf("%s_%d.%s", hash, t.size, ext)
+		*t.dst, err = writeImage(resize(img, t.size), path.Join(relDir, name), ext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stored, nil
+}
+
+// writeImage кодирует изображение в файл относительно Root и возвращает его публичный URL
+func writeImage(img image.Image, relPath, ext string) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	switch ext {
+	case "jpg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(Root, filepath.FromSlash(relPath))
+	if err := os.WriteFile(fullPath, buf.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+
+	return URLPrefix + "/" + relPath, nil
+}
+
+// LocalPath возвращает путь к файлу на диске для URL загруженного файла; false для внешних ссылок
+func LocalPath(url string) (string, bool) {
+	if !strings.HasPrefix(url, URLPrefix+"/") {
+		return "", false
+	}
+	rel := path.Clean("/" + strings.TrimPrefix(url, URLPrefix+"/"))
+	return filepath.Join(Root, filepath.FromSlash(rel)), true
+}
+
+// resize уменьшает изображение так, чтобы большая сторона была не больше maxSide
+func resize(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// applyOrientation поворачивает и отражает изображение согласно тегу EXIF Orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	var dst *image.RGBA
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var nx, ny int
+			switch orientation {
+			case 2:
+				nx, ny = w-1-x, y
+			case 3:
+				nx, ny = w-1-x, h-1-y
+			case 4:
+				nx, ny = x, h-1-y
+			case 5:
+				nx, ny = y, x
+			case 6:
+				nx, ny = h-1-y, x
+			case 7:
+				nx, ny = h-1-y, w-1-x
+			case 8:
+				nx, ny = y, w-1-x
+			}
+			dst.Set(nx, ny, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размер страницы списков по умолчанию и наибольший допустимый
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+// Cursor - позиция в списке: ключ сортировки последней отданной записи.
+// Клиенту отдаётся непрозрачной строкой (next_cursor) и возвращается без изменений.
+type Cursor struct {
+	Sort  string     `json:"s,omitempty"` // режим сортировки, для которого выдан курсор
+	Score int64      `json:"n,omitempty"` // числовой ключ сортировки, например число лайков
+	Time  time.Time  `json:"t"`
+	ID    uint       `json:"id"`
+	AsOf  *time.Time `json:"a,omitempty"` // для "актуальных" - эпоха оценок, из которой считана первая страница
+}
+
+// Page - параметры запроса страницы: limit и cursor
+type Page struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// EncodeCursor упаковывает курсор в строку для ответа
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор из запроса; пустая строка - первая страница (nil)
+func DecodeCursor(value string) (*Cursor, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Time.IsZero() && c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ParsePage читает limit и cursor из параметров запроса; query - например, c.Query.
+// Слишком большой limit уменьшается до maxLimit.
+func ParsePage(query func(string) string, defaultLimit, maxLimit int) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	if v := query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return page, ErrInvalidLimit
+		}
+		page.Limit = min(limit, maxLimit)
+	}
+
+	cursor, err := DecodeCursor(query("cursor"))
+	if err != nil {
+		return page, err
+	}
+	page.Cursor = cursor
+	return page, nil
+}
+
+// KeysetSQL - условие "после курсора" для сортировки по убыванию всех колонок:
+// KeysetSQL("a", "b") = "(a, b) < (?, ?)"
+func KeysetSQL(columns ...string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return "(" + strings.Join(columns, ", ") + ") < (" + placeholders + ")"
+}
+
+// TrimPage обрезает выборку, запрошенную с запасом в одну запись, до limit и сообщает, есть ли ещё записи
+func TrimPage[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+)
+
+const earthRadiusKm = 6371.0
+
+// HaversineKm возвращает расстояние между двумя точками на сфере в километрах
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// RouteDistanceKm возвращает длину ломаной, заданной точками [широта, долгота]
+func RouteDistanceKm(points [][2]float64) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += HaversineKm(points[i-1][0], points[i-1][1], points[i][0], points[i][1])
+	}
+	return math.Round(total*10) / 10
+}
+
+// HaversineSQL - выражение SQL с расстоянием в километрах от точки до столбцов latCol, lonCol.
+// Параметры: широта, широта, долгота точки.
+func HaversineSQL(latCol, lonCol string) string {
+	return "2 * " + strconv.FormatFloat(earthRadiusKm, 'f', -1, 64) + " * asin(sqrt(" +
+		"power(sin(radians(" + latCol + " - ?) / 2), 2) + " +
+		"cos(radians(?)) * cos(radians(" + latCol + ")) * power(sin(radians(" + lonCol + " - ?) / 2), 2)))"
+}
+
+// BoundingBox - прямоугольник [minLat, maxLat, minLon, maxLon], в который попадает круг радиуса radiusKm.
+// Нужен, чтобы отсечь далёкие точки по индексу до точного расчёта расстояния;
+// если круг захватывает полюс или линию перемены дат, долгота не ограничивается.
+func BoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat, maxLat = math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+
+	minLon, maxLon = -180, 180
+	if cosLat := math.Cos(lat * math.Pi / 180); minLat > -90 && maxLat < 90 && cosLat > 0 {
+		dLon := dLat / cosLat
+		if lon-dLon >= -180 && lon+dLon <= 180 {
+			minLon, maxLon = lon-dLon, lon+dLon
+		}
+	}
+	return minLat, maxLat, minLon, maxLon
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Длина шингла в словах: перестановка пары слов меняет лишь несколько шинглов
+	shingleSize = 3
+	// Число хеш-функций MinHash и разбиение подписи на части для поиска кандидатов
+	MinHashSize    = 64
+	minHashBands   = 16
+	minHashPerBand = MinHashSize / minHashBands
+)
+
+// TextWords - слова текста в нижнем регистре без знаков препинания
+func TextWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// mix64 - перемешивание splitmix64; с разными солями даёт независимые хеш-функции
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// MinHash - подпись текста по шинглам из слов. Доля совпадающих позиций двух подписей
+// приближает долю общих шинглов (коэффициент Жаккара) двух текстов.
+func MinHash(words []string) []uint32 {
+	if len(words) == 0 {
+		return nil
+	}
+
+	var shingles []uint64
+	add := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		shingles = append(shingles, h.Sum64())
+	}
+	if len(words) < shingleSize {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(strings.Join(words[i:i+shingleSize], " "))
+	}
+
+	signature := make([]uint32, MinHashSize)
+	for i := range signature {
+		signature[i] = ^uint32(0)
+	}
+	for _, shingle := range shingles {
+		for i := range signature {
+			if v := uint32(mix64(shingle ^ uint64(i+1)*0x9e3779b97f4a7c15)); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// MinHashSimilarity - оценка сходства текстов по подписям, от 0 до 1
+func MinHashSimilarity(a, b []uint32) float64 {
+	if len(a) != MinHashSize || len(b) != MinHashSize {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / MinHashSize
+}
+
+// MinHashBands - хеши частей подписи. У текстов со сходством от 0.7 хотя бы одна часть совпадает
+// почти наверняка, у непохожих - почти никогда; по частям ищутся кандидаты в базе.
+func MinHashBands(signature []uint32) []int64 {
+	if len(signature) != MinHashSize {
+		return nil
+	}
+	bands := make([]int64, minHashBands)
+	for band := range bands {
+		h := fnv.New64a()
+		for _, v := range signature[band*minHashPerBand : (band+1)*minHashPerBand] {
+			h.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)})
+		}
+		bands[band] = int64(h.Sum64())
+	}
+	return bands
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Максимальная длина slug; длинные заголовки обрезаются по границе слова
+const maxSlugLength = 80
+
+// Транслитерация кириллицы (русский и белорусский алфавиты) в латиницу
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ў': "w",
+}
+
+// В белорусском г - фрикативный: Гродна -> hrodna, Магілёў -> mahilyow
+var belarusianOverrides = map[rune]string{
+	'г': "h",
+}
+
+// Transliterate переводит кириллицу в латиницу; lang = "be" включает белорусские правила
+func Transliterate(s, lang string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if lang == "be" {
+			if latin, ok := belarusianOverrides[r]; ok {
+				b.WriteString(latin)
+				continue
+			}
+		}
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Slugify строит slug для адреса поста: "Замак у Міры" -> "zamak-u-miry".
+// Пустой результат заменяется на "post".
+func Slugify(title, lang string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range Transliterate(normalize(title), lang) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.Trim(slug, "-")
+	}
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"padaroja/internal/domain/models"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Максимальная длина названия тега (как у колонки tags.name)
+const maxTagLength = 150
+
+// NormalizeTagName приводит тег к каноничному виду: без '#', пробелов по краям и повторных пробелов, в нижнем регистре.
+// "  #Замок  у  Міры " -> "замок у міры"
+func NormalizeTagName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, "#")
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	for utf8.RuneCountInString(name) > maxTagLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return strings.TrimSpace(name)
+}
+
+// FindTag ищет основной тег по нормализованному названию или его синониму.
+// Если тег найден по синониму, вторым значением возвращается название синонима.
+func FindTag(db *gorm.DB, name string) (models.Tags, string, bool) {
+	var tag models.Tags
+	if err := db.Where("name = ?", name).First(&tag).Error; err == nil {
+		return tag, "", true
+	}
+
+	var synonym models.TagSynonym
+	if err := db.Preload("Tag").Where("name = ?", name).First(&synonym).Error; err == nil && synonym.Tag.ID != 0 {
+		return synonym.Tag, synonym.Name, true
+	}
+	return models.Tags{}, "", false
+}
+
+// EscapeLike экранирует спецсимволы шаблона LIKE
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Средняя скорость чтения и время на разглядывание одной фотографии
+const (
+	wordsPerMinute  = 200
+	secondsPerPhoto = 10
+)
+
+var (
+	mdLinkTextRe = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	mdMarkupRe   = regexp.MustCompile("[*_~`]+")
+	mdLinePrefix = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-+]|\d+\.)\s+`)
+)
+
+// MarkdownToPlain убирает inline-разметку markdown: ссылки заменяются текстом, выделение и маркеры строк удаляются
+func MarkdownToPlain(s string) string {
+	s = mdLinkTextRe.ReplaceAllString(s, "$1")
+	s = mdLinePrefix.ReplaceAllString(s, "")
+	s = mdMarkupRe.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// CountWords считает слова - последовательности, в которых есть хотя бы одна буква или цифра
+func CountWords(s string) int {
+	count := 0
+	for _, field := range strings.Fields(s) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Excerpt обрезает текст до maxRunes символов по границе слова и добавляет многоточие
+func Excerpt(s string, maxRunes int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+
+	runes := []rune(s)
+	cut := string(runes[:maxRunes])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	cut = strings.TrimRightFunc(cut, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsPunct(r) })
+	return cut + "…"
+}
+
+// ReadingMinutes - оценка времени чтения в минутах, не меньше одной
+func ReadingMinutes(words, photos int) int {
+	seconds := words*60/wordsPerMinute + photos*secondsPerPhoto
+	minutes := (seconds + 59) / 60
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
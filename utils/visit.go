@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Формат дат поездки в запросах: 2025-01-31
+const DateLayout = "2006-01-02"
+
+// Месяцы сезонов; принимаются английские и русские/белорусские названия
+var seasonMonths = map[string][]int{
+	"winter": {12, 1, 2}, "зима": {12, 1, 2},
+	"spring": {3, 4, 5}, "весна": {3, 4, 5}, "вясна": {3, 4, 5},
+	"summer": {6, 7, 8}, "лето": {6, 7, 8}, "лета": {6, 7, 8},
+	"autumn": {9, 10, 11}, "fall": {9, 10, 11}, "осень": {9, 10, 11}, "восень": {9, 10, 11},
+}
+
+// VisitFilter - фильтр постов по датам поездки (visited_from, visited_to, month, season, year)
+type VisitFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Months []int
+	Year   int
+}
+
+// ParseDate разбирает дату в формате DateLayout; пустая строка - nil
+func ParseDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return &t, nil
+}
+
+// ParseVisitFilter читает фильтр из параметров запроса; query - например, c.Query
+func ParseVisitFilter(query func(string) string) (VisitFilter, error) {
+	var filter VisitFilter
+	var err error
+
+	if filter.From, err = ParseDate(query("visited_from")); err != nil {
+		return filter, err
+	}
+	if filter.To, err = ParseDate(query("visited_to")); err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, fmt.Errorf("visited_to must not be before visited_from")
+	}
+
+	seen := make(map[int]bool)
+	addMonth := func(m int) {
+		if !seen[m] {
+			seen[m] = true
+			filter.Months = append(filter.Months, m)
+		}
+	}
+	if v := strings.TrimSpace(query("month")); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			m, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || m < 1 || m > 12 {
+				return filter, fmt.Errorf("invalid month %q, expected 1-12", part)
+			}
+			addMonth(m)
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(query("season"))); v != "" {
+		months, ok := seasonMonths[v]
+		if !ok {
+			return filter, fmt.Errorf("unknown season %q, expected winter, spring, summer or autumn", v)
+		}
+		for _, m := range months {
+			addMonth(m)
+		}
+	}
+
+	if v := strings.TrimSpace(query("year")); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1900 || year > 2100 {
+			return filter, fmt.Errorf("invalid year %q", v)
+		}
+		filter.Year = year
+	}
+
+	return filter, nil
+}
+
+// IsEmpty - фильтр не задан
+func (f VisitFilter) IsEmpty() bool {
+	return f.From == nil && f.To == nil && len(f.Months) == 0 && f.Year == 0
+}
+
+// SQL - условие WHERE для таблицы posts. Поездка подходит, если хотя бы один её день попадает в фильтр;
+// посты без дат поездки при заданном фильтре не попадают в выборку.
+func (f VisitFilter) SQL() (string, []interface{}) {
+	if f.IsEmpty() {
+		return "", nil
+	}
+
+	const tripEnd = "COALESCE(posts.visited_to, posts.visited_from)"
+	conditions := []string{"posts.visited_from IS NOT NULL"}
+	var args []interface{}
+
+	if f.From != nil {
+		conditions = append(conditions, tripEnd+" >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conditions = append(conditions, "posts.visited_from <= ?")
+		args = append(args, *f.To)
+	}
+
+	if len(f.Months) > 0 {
+		// Перебираем месяцы поездки: зимняя поездка 28.02-02.03 подходит и под зиму, и под весну
+		monthCondition := "EXTRACT(MONTH FROM m)::int IN ?"
+		args = append(args, f.Months)
+		if f.Year != 0 {
+			monthCondition += " AND EXTRACT(YEAR FROM m)::int = ?"
+			args = append(args, f.Year)
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM generate_series(date_trunc('month', posts.visited_from), %s, interval '1 month') AS m WHERE %s)",
+			tripEnd, monthCondition,
+		))
+	} else if f.Year != 0 {
+		conditions = append(conditions, "EXTRACT(YEAR FROM posts.visited_from)::int <= ? AND EXTRACT(YEAR FROM "+tripEnd+")::int >= ?")
+		args = append(args, f.Year, f.Year)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// Scope - SQL в виде scope для gorm: db.Scopes(filter.Scope)
+func (f VisitFilter) Scope(db *gorm.DB) *gorm.DB {
+	if condition, args := f.SQL(); condition != "" {
+		return db.Where(condition, args...)
+	}
+	return db
+}
@@ -113,15 +113,20 @@ const AllPostsMap: React.FC<AllPostsMapProps> = ({ onPlaceSelect }) => {
         setError(null);
         
         console.log('Загрузка постов для карты...');
-        const response = await axios.get('/api/map/posts/all', {
-          withCredentials: true,
-          timeout: 30000
-        });
-        
-        console.log('Ответ от API:', response.data);
+        // Маркеры отдаются страницами - догружаем, пока есть следующая
+        const postsData: PostMarker[] = [];
+        let cursor: string | null = null;
+        do {
+          const response = await axios.get('/api/map/posts/all', {
+            params: cursor ? { cursor } : undefined,
+            withCredentials: true,
+            timeout: 30000
+          });
+          postsData.push(...(response.data?.posts || []));
+          cursor = response.data?.next_cursor || null;
+        } while (cursor);
         
-        if (response.data && response.data.posts) {
-          const postsData = response.data.posts;
+        if (postsData.length > 0) {
           console.log(`Загружено ${postsData.length} постов`);
           
           const validPosts = postsData.filter((p: PostMarker) => 
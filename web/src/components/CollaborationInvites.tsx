@@ -3,6 +3,7 @@ import axios from 'axios';
 import { useNavigate } from 'react-router-dom';
 import { FaUserPlus, FaCheck, FaTimes, FaBell, FaPaperPlane, FaCheckCircle, FaTimesCircle, FaClock, FaEye } from 'react-icons/fa';
 import ContentLayout from './ContentLayout.tsx';
+import NotificationsList from './NotificationsList.tsx';
 import './CollaborationInvites.css';
 
 interface Invite {
@@ -36,7 +37,7 @@ interface SentInvite {
 }
 
 const CollaborationInvites: React.FC = () => {
-    const [activeTab, setActiveTab] = useState<'incoming' | 'outgoing'>('incoming');
+    const [activeTab, setActiveTab] = useState<'incoming' | 'outgoing' | 'notifications'>('incoming');
     const [incomingInvites, setIncomingInvites] = useState<Invite[]>([]);
     const [outgoingInvites, setOutgoingInvites] = useState<SentInvite[]>([]);
     const [loading, setLoading] = useState(true);
@@ -237,6 +238,23 @@ const CollaborationInvites: React.FC = () => {
                         <FaPaperPlane style={{ marginRight: '8px' }} />
                         Исходящие
                     </button>
+                    <button
+                        onClick={() => setActiveTab('notifications')}
+                        style={{
+                            padding: '10px 20px',
+                            border: 'none',
+                            background: 'none',
+                            cursor: 'pointer',
+                            fontSize: '16px',
+                            fontWeight: activeTab === 'notifications' ? '600' : '400',
+                            color: activeTab === 'notifications' ? '#696cff' : '#666',
+                            borderBottom: activeTab === 'notifications' ? '2px solid #696cff' : 'none',
+                            transition: 'none'
+                        }}
+                    >
+                        <FaBell style={{ marginRight: '8px' }} />
+                        Уведомления
+                    </button>
                 </div>
 
                 {/* Центрируем контент */}
@@ -425,6 +443,9 @@ const CollaborationInvites: React.FC = () => {
                             )}
                         </>
                     )}
+
+                    {/* Уведомления по сохранённым поискам */}
+                    {activeTab === 'notifications' && <NotificationsList />}
                 </div>
             </div>
         </ContentLayout>
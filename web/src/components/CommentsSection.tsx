@@ -44,25 +44,27 @@ const CommentsSection: React.FC<CommentsSectionProps> = ({
       setError('');
       
       // Проверяем, существует ли эндпоинт, пробуем разные варианты
-      let response;
-      try {
-        response = await axios.get<CommentsResponse>(
-          `/api/comments/post/${postId}`,
-          { withCredentials: true }
-        );
-      } catch (err: any) {
-        if (err.response?.status === 404) {
-          // Пробуем альтернативный эндпоинт
-          response = await axios.get<CommentsResponse>(
-            `/api/posts/${postId}/comments`,
-            { withCredentials: true }
-          );
-        } else {
+      const fetchPage = async (cursor: string | null) => {
+        const config = { params: cursor ? { cursor } : undefined, withCredentials: true };
+        try {
+          return await axios.get<CommentsResponse>(`/api/comments/post/${postId}`, config);
+        } catch (err: any) {
+          if (err.response?.status === 404) {
+            // Пробуем альтернативный эндпоинт
+            return await axios.get<CommentsResponse>(`/api/posts/${postId}/comments`, config);
+          }
           throw err;
         }
-      }
-      
-      const allComments = response.data.comments || response.data || [];
+      };
+
+      // Комментарии отдаются страницами - загружаем все, чтобы собрать ветки ответов
+      const allComments: Comment[] = [];
+      let cursor: string | null = null;
+      do {
+        const response = await fetchPage(cursor);
+        allComments.push(...(response.data.comments || []));
+        cursor = response.data.next_cursor || null;
+      } while (cursor);
       
       // Фильтруем корневые комментарии
       const rootComments = allComments.filter((comment: Comment) => !comment.parent_id);
@@ -1,6 +1,6 @@
-import React, { useState } from 'react';
+import React, { useMemo, useState } from 'react';
 import PostFeed from '../components/PostFeed.tsx';
-import RightFilters from '../components/RightFilters.tsx';
+import RightFilters, { ExtraFilters, defaultExtraFilters } from '../components/RightFilters.tsx';
 import ContentLayout from '../components/ContentLayout.tsx';
 import { useAuth } from '../context/AuthContext.tsx';
 import '../components/UserPostsFeed.css';
@@ -9,8 +9,23 @@ const FeedPage: React.FC = () => {
     const [searchTerm, setSearchTerm] = useState('');
     const [tagSearch, setTagSearch] = useState('');
     const [sortBy, setSortBy] = useState('new'); // 'popular', 'new', 'trending'
+    const [extraFilters, setExtraFilters] = useState<ExtraFilters>(defaultExtraFilters);
     const { isLoading } = useAuth();
 
+    // Параметры запроса ленты из дополнительных фильтров
+    const filters = useMemo(() => {
+        const params: Record<string, string> = {};
+        if (extraFilters.hasPhotos) params.has_photos = 'true';
+        if (extraFilters.minLikes > 0) params.min_likes = String(extraFilters.minLikes);
+        if (extraFilters.tagsMode === 'any') params.tags_mode = 'any';
+        if (extraFilters.near && extraFilters.radiusKm > 0) {
+            params.lat = String(extraFilters.near.lat);
+            params.lon = String(extraFilters.near.lon);
+            params.radius_km = String(extraFilters.radiusKm);
+        }
+        return params;
+    }, [extraFilters]);
+
     if (isLoading) {
         return (
             <div style={{ display: 'flex', justifyContent: 'center', alignItems: 'center', height: '100vh' }}>
@@ -27,6 +42,7 @@ const FeedPage: React.FC = () => {
                         searchQuery={searchTerm} 
                         tagQuery={tagSearch} 
                         sortBy={sortBy} // Добавляем
+                        filters={filters}
                     />
                 </div>
 
@@ -38,6 +54,8 @@ const FeedPage: React.FC = () => {
                         setTagSearch={setTagSearch}
                         sortBy={sortBy} // Добавляем
                         setSortBy={setSortBy} // Добавляем
+                        extraFilters={extraFilters}
+                        setExtraFilters={setExtraFilters}
                     />
                 </div>
             </div>
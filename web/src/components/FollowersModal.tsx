@@ -44,8 +44,18 @@ const FollowersModal: React.FC<FollowersModalProps> = ({
                 ? `/api/user/${userId}/followers`
                 : `/api/user/${userId}/following`;
             
-            const response = await axios.get(endpoint, { withCredentials: true });
-            setUsers(response.data[type] || []);
+            // Список отдаётся страницами - загружаем все
+            const loaded: User[] = [];
+            let cursor: string | null = null;
+            do {
+                const response = await axios.get(endpoint, {
+                    params: cursor ? { cursor } : undefined,
+                    withCredentials: true
+                });
+                loaded.push(...(response.data[type] || []));
+                cursor = response.data.next_cursor || null;
+            } while (cursor);
+            setUsers(loaded);
         } catch (error) {
             console.error('Error fetching users:', error);
         } finally {
@@ -0,0 +1,188 @@
+import React, { useEffect, useState, useCallback } from 'react';
+import axios from 'axios';
+import { useNavigate } from 'react-router-dom';
+import { FaBell, FaSearch, FaTrash } from 'react-icons/fa';
+import './CollaborationInvites.css';
+import './UserPostsFeed.css';
+
+interface Notification {
+    id: number;
+    type: string;
+    post_id?: number;
+    saved_search_id?: number;
+    text: string;
+    is_read: boolean;
+    created_at: string;
+}
+
+interface SavedSearch {
+    id: number;
+    name: string;
+    query: string;
+    tags: string;
+    tags_any: boolean;
+    admin1_code: string;
+    radius_km: number;
+}
+
+// Уведомления о новых постах по сохранённым поискам и сами сохранённые поиски
+const NotificationsList: React.FC = () => {
+    const [notifications, setNotifications] = useState<Notification[]>([]);
+    const [savedSearches, setSavedSearches] = useState<SavedSearch[]>([]);
+    const [nextCursor, setNextCursor] = useState<string | null>(null);
+    const [loading, setLoading] = useState(true);
+    const navigate = useNavigate();
+
+    const fetchNotifications = useCallback(async (cursor?: string) => {
+        try {
+            const params = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+            const response = await axios.get(`/api/notifications${params}`, { withCredentials: true });
+            const page: Notification[] = response.data.notifications || [];
+            setNotifications(prev => cursor ? [...prev, ...page] : page);
+            setNextCursor(response.data.next_cursor || null);
+        } catch (error) {
+            console.error('Ошибка загрузки уведомлений:', error);
+        }
+    }, []);
+
+    const fetchSavedSearches = useCallback(async () => {
+        try {
+            const response = await axios.get('/api/saved-searches', { withCredentials: true });
+            setSavedSearches(response.data.saved_searches || []);
+        } catch (error) {
+            console.error('Ошибка загрузки сохранённых поисков:', error);
+        }
+    }, []);
+
+    useEffect(() => {
+        Promise.all([fetchNotifications(), fetchSavedSearches()]).finally(() => setLoading(false));
+
+        // Новые уведомления приходят по личному потоку
+        const eventSource = new EventSource('/api/notifications/stream', { withCredentials: true });
+        eventSource.onmessage = (event) => {
+            try {
+                const message = JSON.parse(event.data);
+                if (message.type === 'SAVED_SEARCH_MATCH' && message.data?.notification) {
+                    setNotifications(prev => [message.data.notification, ...prev]);
+                }
+            } catch (error) {
+                console.error('Error parsing SSE message:', error);
+            }
+        };
+        return () => eventSource.close();
+    }, [fetchNotifications, fetchSavedSearches]);
+
+    const openNotification = async (notification: Notification) => {
+        if (!notification.is_read) {
+            setNotifications(prev => prev.map(n => n.id === notification.id ? { ...n, is_read: true } : n));
+            axios.put(`/api/notifications/${notification.id}/read`, {}, { withCredentials: true })
+                .catch(error => console.error('Ошибка отметки уведомления:', error));
+        }
+        if (notification.post_id) {
+            navigate(`/post/${notification.post_id}`);
+        }
+    };
+
+    const markAllRead = async () => {
+        try {
+            await axios.put('/api/notifications/read', {}, { withCredentials: true });
+            setNotifications(prev => prev.map(n => ({ ...n, is_read: true })));
+        } catch (error) {
+            console.error('Ошибка отметки уведомлений:', error);
+        }
+    };
+
+    const deleteSavedSearch = async (id: number) => {
+        if (!window.confirm('Удалить сохранённый поиск?')) return;
+        try {
+            await axios.delete(`/api/saved-searches/${id}`, { withCredentials: true });
+            setSavedSearches(prev => prev.filter(s => s.id !== id));
+        } catch (error) {
+            console.error('Ошибка удаления сохранённого поиска:', error);
+        }
+    };
+
+    const describeSearch = (search: SavedSearch) => {
+        const parts: string[] = [];
+        if (search.query) parts.push(`«${search.query}»`);
+        if (search.tags) parts.push(`теги: ${search.tags}${search.tags_any ? ' (любой)' : ''}`);
+        if (search.admin1_code) parts.push(`регион ${search.admin1_code}`);
+        if (search.radius_km > 0) parts.push(`в радиусе ${search.radius_km} км`);
+        return parts.join(', ');
+    };
+
+    if (loading) {
+        return (
+            <div className="invites-loading">
+                <FaBell className="loading-icon" />
+                <span>Загрузка уведомлений...</span>
+            </div>
+        );
+    }
+
+    return (
+        <div className="invites-container" style={{ maxWidth: '600px', width: '100%' }}>
+            {savedSearches.length > 0 && (
+                <>
+                    <h3 className="invites-title"><FaSearch /> Сохранённые поиски</h3>
+                    <div className="invites-list" style={{ marginBottom: '24px' }}>
+                        {savedSearches.map(search => (
+                            <div key={search.id} className="invite-card">
+                                <div className="invite-header">
+                                    <div className="invite-info">
+                                        <div className="inviter-name">{search.name}</div>
+                                        <div>{describeSearch(search)}</div>
+                                    </div>
+                                    <button className="decline-btn" onClick={() => deleteSavedSearch(search.id)}>
+                                        <FaTrash />
+                                    </button>
+                                </div>
+                            </div>
+                        ))}
+                    </div>
+                </>
+            )}
+
+            <h3 className="invites-title">
+                <FaBell /> Уведомления
+                {notifications.some(n => !n.is_read) && (
+                    <button className="load-more-button" style={{ marginLeft: 'auto' }} onClick={markAllRead}>
+                        Прочитать все
+                    </button>
+                )}
+            </h3>
+
+            {notifications.length === 0 ? (
+                <div className="invites-empty">
+                    <FaBell className="empty-icon" />
+                    <p>Уведомлений пока нет</p>
+                    <span className="empty-hint">
+                        Сохраните поиск в ленте, и мы сообщим о новых подходящих постах
+                    </span>
+                </div>
+            ) : (
+                <div className="invites-list">
+                    {notifications.map(notification => (
+                        <div
+                            key={notification.id}
+                            className="invite-card"
+                            style={{ cursor: 'pointer', fontWeight: notification.is_read ? 400 : 600 }}
+                            onClick={() => openNotification(notification)}
+                        >
+                            <div>{notification.text}</div>
+                            <div className="empty-hint">{new Date(notification.created_at).toLocaleString('ru-RU')}</div>
+                        </div>
+                    ))}
+                </div>
+            )}
+
+            {nextCursor && (
+                <button className="load-more-button" onClick={() => fetchNotifications(nextCursor)}>
+                    Показать ещё
+                </button>
+            )}
+        </div>
+    );
+};
+
+export default NotificationsList;
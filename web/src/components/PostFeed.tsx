@@ -16,6 +16,11 @@ interface PostData {
     tags: string[];
     photos: { url: string }[];
     likes_count: number;
+    preview_text?: string;
+    reading_time_minutes?: number;
+    // Только в результатах поиска: отрывки с подсветкой <mark>, экранированные сервером
+    title_highlight?: string;
+    highlight?: string;
     user_id: number;
     user_avatar: string;
     user_name: string;
@@ -27,6 +32,8 @@ interface PostFeedProps {
     searchQuery?: string;
     tagQuery?: string;
     sortBy?: string;
+    // Дополнительные фильтры ленты: has_photos, min_likes, tags_mode, lat/lon и radius_km и т.п.
+    filters?: Record<string, string>;
     isFavourites?: boolean;
     isLikes?: boolean;
 }
@@ -36,10 +43,18 @@ interface SSEMessage {
     data: any;
 }
 
+// mergeStatuses обновляет отметки (избранное, лайки) только для постов из postIds
+const mergeStatuses = (prev: Set<number>, postIds: number[], marked: Set<number>) => {
+    const next = new Set(prev);
+    postIds.forEach(id => marked.has(id) ? next.add(id) : next.delete(id));
+    return next;
+};
+
 const PostFeed: React.FC<PostFeedProps> = ({
     searchQuery = '',
     tagQuery = '',
     sortBy = 'new',
+    filters,
     isFavourites = false,
     isLikes = false
 }) => {
@@ -52,6 +67,9 @@ const PostFeed: React.FC<PostFeedProps> = ({
     const [processingLike, setProcessingLike] = useState<Set<number>>(new Set());
     const [sseConnected, setSseConnected] = useState(false);
     const [likesCounts, setLikesCounts] = useState<Map<number, number>>(new Map());
+    // Курсор следующей страницы; null - всё загружено
+    const [nextCursor, setNextCursor] = useState<string | null>(null);
+    const [loadingMore, setLoadingMore] = useState(false);
 
     const eventSourceRef = useRef<EventSource | null>(null);
     const reconnectTimeoutRef = useRef<NodeJS.Timeout>();
@@ -87,7 +105,8 @@ const PostFeed: React.FC<PostFeedProps> = ({
             Object.entries(favResponse.data).forEach(([id, isFav]) => {
                 if (isFav) favouriteIds.add(parseInt(id));
             });
-            setFavourites(favouriteIds);
+            // Статусы объединяются с уже загруженными страницами
+            setFavourites(prev => mergeStatuses(prev, postIds, favouriteIds));
 
             // Загружаем статусы лайков по одному (более надежно)
             const likedIds = new Set<number>();
@@ -106,7 +125,7 @@ const PostFeed: React.FC<PostFeedProps> = ({
                 }
             }
             
-            setLikes(likedIds);
+            setLikes(prev => mergeStatuses(prev, postIds, likedIds));
 
         } catch (err) {
             console.error("Ошибка при загрузке статусов:", err);
@@ -132,7 +151,7 @@ const PostFeed: React.FC<PostFeedProps> = ({
                 newLikesCounts.set(postIds[index], result.data.likes_count);
             });
 
-            setLikesCounts(newLikesCounts);
+            setLikesCounts(prev => new Map([...prev, ...newLikesCounts]));
 
             setPosts(prev => prev.map(post => ({
                 ...post,
@@ -143,62 +162,78 @@ const PostFeed: React.FC<PostFeedProps> = ({
         }
     }, []);
 
-    // Загрузка постов
-    const loadPosts = useCallback(async () => {
-        setLoading(true);
-        setError('');
-
-        try {
-            let url = '/api/posts';
-            const params = new URLSearchParams();
+    // Загрузка страницы постов; cursor - продолжение уже показанной ленты
+    // Ключ фильтров для зависимостей: объект пересоздаётся при каждом рендере родителя
+    const filtersKey = new URLSearchParams(filters || {}).toString();
+
+    const fetchPage = useCallback(async (cursor?: string) => {
+        let url = '/api/posts';
+        const params = new URLSearchParams();
+        // С текстом запроса лента превращается в полнотекстовый поиск: по релевантности или по дате
+        const isSearch = !isFavourites && !isLikes && searchQuery.trim() !== '';
+
+        if (isFavourites) {
+            url = '/api/favourites';
+        } else if (isLikes) {
+            url = '/api/likes';
+        } else if (isSearch) {
+            url = '/api/posts/search';
+            params.append('q', searchQuery.trim());
+            if (tagQuery) params.append('tags', tagQuery);
+            params.append('sort', sortBy === 'new' ? 'new' : 'relevance');
+        } else {
+            if (tagQuery) params.append('tags', tagQuery);
+            if (sortBy) params.append('sort', sortBy);
+        }
+        if (!isFavourites && !isLikes) {
+            new URLSearchParams(filtersKey).forEach((value, key) => params.append(key, value));
+        }
+        if (cursor) params.append('cursor', cursor);
 
-            if (isFavourites) {
-                url = '/api/favourites';
-            } else if (isLikes) {
-                url = '/api/likes';
-            } else {
-                if (searchQuery) params.append('search', searchQuery);
-                if (tagQuery) params.append('tags', tagQuery);
-                if (sortBy) params.append('sort', sortBy);
+        const response = await axios.get<{ posts?: PostData[]; results?: PostData[]; next_cursor: string | null }>(
+            `${url}?${params.toString()}`,
+            { 
+                withCredentials: true,
+                timeout: 10000
             }
+        );
 
-            const response = await axios.get<PostData[]>(
-                isFavourites || isLikes ? url : `${url}?${params.toString()}`,
-                { 
-                    withCredentials: true,
-                    timeout: 10000
-                }
-            );
-
-            let postsData = response.data || [];
-            
-            // Нормализуем данные
-            postsData = postsData.map(post => ({
-                ...post,
-                likes_count: post.likes_count || 0
-            }));
-            
-            setPosts(postsData);
-
-            // Загружаем дополнительные данные
-            if (postsData.length > 0) {
-                const postIds = postsData.map(post => post.id);
+        // Нормализуем данные
+        const postsData = (response.data?.posts || response.data?.results || []).map(post => ({
+            ...post,
+            likes_count: post.likes_count || 0
+        }));
+        setNextCursor(response.data?.next_cursor || null);
+        return postsData;
+    }, [searchQuery, tagQuery, sortBy, filtersKey, isFavourites, isLikes]);
+
+    // Загружаем дополнительные данные для новых постов
+    const loadPostsExtras = useCallback(async (postsData: PostData[]) => {
+        if (postsData.length === 0) return;
+        const postIds = postsData.map(post => post.id);
+
+        if (isFavourites) {
+            setFavourites(prev => new Set<number>([...prev, ...postIds]));
+        } else if (isLikes) {
+            setLikes(prev => new Set<number>([...prev, ...postIds]));
+        }
 
-                if (isFavourites) {
-                    const favouriteIds = new Set<number>(postIds);
-                    setFavourites(favouriteIds);
-                } else if (isLikes) {
-                    const likedIds = new Set<number>(postIds);
-                    setLikes(likedIds);
-                }
+        if (isLoggedIn && !isFavourites && !isLikes) {
+            await loadInteractionStatuses(postIds);
+        }
 
-                if (isLoggedIn && !isFavourites && !isLikes) {
-                    await loadInteractionStatuses(postIds);
-                }
+        await loadLikesCounts(postIds);
+    }, [isFavourites, isLikes, isLoggedIn, loadInteractionStatuses, loadLikesCounts]);
 
-                await loadLikesCounts(postIds);
-            }
+    // Загрузка постов
+    const loadPosts = useCallback(async () => {
+        setLoading(true);
+        setError('');
 
+        try {
+            const postsData = await fetchPage();
+            setPosts(postsData);
+            await loadPostsExtras(postsData);
         } catch (err: any) {
             console.error("Ошибка при получении постов:", err);
             if (err.code === 'ECONNABORTED') {
@@ -215,7 +250,30 @@ const PostFeed: React.FC<PostFeedProps> = ({
         } finally {
             setLoading(false);
         }
-    }, [searchQuery, tagQuery, sortBy, isFavourites, isLikes, isLoggedIn, loadInteractionStatuses, loadLikesCounts]);
+    }, [fetchPage, loadPostsExtras, isFavourites, isLikes]);
+
+    // Следующая страница ленты
+    const loadMore = useCallback(async () => {
+        if (!nextCursor || loadingMore) return;
+        setLoadingMore(true);
+        try {
+            const postsData = await fetchPage(nextCursor);
+            // Пост мог прийти по SSE раньше, чем до него дошла лента
+            setPosts(prev => {
+                const known = new Set(prev.map(post => post.id));
+                return [...prev, ...postsData.filter(post => !known.has(post.id))];
+            });
+            await loadPostsExtras(postsData);
+        } catch (err: any) {
+            console.error("Ошибка при загрузке следующей страницы:", err);
+            // Оценки "актуальных" пересчитаны - старый курсор недействителен, загружаем ленту заново
+            if (err.response?.status === 409) {
+                await loadPosts();
+            }
+        } finally {
+            setLoadingMore(false);
+        }
+    }, [nextCursor, loadingMore, fetchPage, loadPostsExtras, loadPosts]);
 
     // SSE подключение - НЕ подключаемся на страницах избранного и лайков
     useEffect(() => {
@@ -255,6 +313,9 @@ const PostFeed: React.FC<PostFeedProps> = ({
                         case 'NEW_POST': {
                             const newPost = message.data as PostData;
 
+                            // Расстояние, регион и лайки нового поста на клиенте не проверить - ждём обновления ленты
+                            if (filtersKey) return;
+
                             if (searchQuery) {
                                 const matchesSearch = 
                                     newPost.title?.toLowerCase().includes(searchQuery.toLowerCase()) ||
@@ -341,7 +402,7 @@ const PostFeed: React.FC<PostFeedProps> = ({
                 eventSourceRef.current = null;
             }
         };
-    }, [searchQuery, tagQuery, sortBy, isFavourites, isLikes, isLoggedIn, loadInteractionStatuses, loadLikesCounts]);
+    }, [searchQuery, tagQuery, sortBy, filtersKey, isFavourites, isLikes, isLoggedIn, loadInteractionStatuses, loadLikesCounts]);
 
     // Загрузка постов при изменении параметров
     useEffect(() => {
@@ -685,10 +746,25 @@ const PostFeed: React.FC<PostFeedProps> = ({
                         </div>
 
                         <div className="post-header-row-new">
-                            <span className="post-title-new">{post.title}</span>
+                            {post.title_highlight ? (
+                                <span className="post-title-new" dangerouslySetInnerHTML={{ __html: post.title_highlight }} />
+                            ) : (
+                                <span className="post-title-new">{post.title}</span>
+                            )}
                             <span className="post-date-new">{formatDate(post.created_at)}</span>
                         </div>
 
+                        {(post.highlight || post.preview_text) && (
+                            <p className="post-preview-new">
+                                {post.highlight ? (
+                                    <span dangerouslySetInnerHTML={{ __html: post.highlight }} />
+                                ) : post.preview_text}
+                                {post.reading_time_minutes ? (
+                                    <span className="post-reading-time-new"> · {post.reading_time_minutes} мин</span>
+                                ) : null}
+                            </p>
+                        )}
+
                         <div className="post-footer-new">
                             <div className="post-meta-left-new">
                                 <span className="post-place-new">{post.settlement_name}</span>
@@ -731,6 +807,14 @@ const PostFeed: React.FC<PostFeedProps> = ({
                     </div>
                 ))}
             </div>
+
+            {nextCursor && (
+                <div className="posts-load-more">
+                    <button onClick={loadMore} className="load-more-button" disabled={loadingMore}>
+                        {loadingMore ? 'Загрузка...' : 'Показать ещё'}
+                    </button>
+                </div>
+            )}
         </>
     );
 };
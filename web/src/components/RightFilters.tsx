@@ -1,9 +1,28 @@
 // RightFilters.tsx - исправленная версия
 import React, { useState, useEffect } from 'react';
-import { FaSearch, FaMap, FaFire } from 'react-icons/fa';
+import { FaSearch, FaMap, FaFire, FaBell } from 'react-icons/fa';
 import { useNavigate } from 'react-router-dom';
+import axios from 'axios';
+import { useAuth } from '../context/AuthContext.tsx';
 import '../components/RightFilters.css';
 
+// Дополнительные фильтры ленты
+export interface ExtraFilters {
+    hasPhotos: boolean;
+    minLikes: number;
+    tagsMode: 'all' | 'any';
+    radiusKm: number;
+    near: { lat: number; lon: number } | null;
+}
+
+export const defaultExtraFilters: ExtraFilters = {
+    hasPhotos: false,
+    minLikes: 0,
+    tagsMode: 'all',
+    radiusKm: 50,
+    near: null,
+};
+
 interface RightFiltersProps {
     searchTerm: string;
     setSearchTerm: (value: string) => void;
@@ -11,6 +30,8 @@ interface RightFiltersProps {
     setTagSearch: (value: string) => void;
     sortBy: string;
     setSortBy: (value: string) => void;
+    extraFilters?: ExtraFilters;
+    setExtraFilters?: (value: ExtraFilters) => void;
 }
 
 interface GrowingUser {
@@ -26,13 +47,77 @@ const RightFilters: React.FC<RightFiltersProps> = ({
     tagSearch, 
     setTagSearch,
     sortBy,
-    setSortBy
+    setSortBy,
+    extraFilters,
+    setExtraFilters
 }) => {
     const navigate = useNavigate();
     const [topUsers, setTopUsers] = useState<GrowingUser[]>([]);
     const [topUsersLoading, setTopUsersLoading] = useState(false);
     const [topUsersError, setTopUsersError] = useState<string | null>(null);
 
+    const { isLoggedIn } = useAuth();
+    const [savingSearch, setSavingSearch] = useState(false);
+    const [locating, setLocating] = useState(false);
+    const [locationError, setLocationError] = useState<string | null>(null);
+
+    const updateExtraFilters = (patch: Partial<ExtraFilters>) => {
+        if (extraFilters && setExtraFilters) {
+            setExtraFilters({ ...extraFilters, ...patch });
+        }
+    };
+
+    // Радиус отсчитывается от текущего местоположения пользователя
+    const toggleNearMe = () => {
+        if (!extraFilters) return;
+        if (extraFilters.near) {
+            updateExtraFilters({ near: null });
+            return;
+        }
+        if (!navigator.geolocation) {
+            setLocationError('Геолокация недоступна в этом браузере');
+            return;
+        }
+        setLocating(true);
+        setLocationError(null);
+        navigator.geolocation.getCurrentPosition(
+            (position) => {
+                setLocating(false);
+                updateExtraFilters({ near: { lat: position.coords.latitude, lon: position.coords.longitude } });
+            },
+            () => {
+                setLocating(false);
+                setLocationError('Не удалось определить местоположение');
+            }
+        );
+    };
+
+    // Сохраняет текущий запрос ленты; о новых подходящих постах придёт уведомление
+    const handleSaveSearch = async () => {
+        const name = window.prompt('Название поиска', searchTerm || tagSearch || 'Мой поиск');
+        if (!name || !name.trim()) return;
+
+        setSavingSearch(true);
+        try {
+            await axios.post('/api/saved-searches', {
+                name: name.trim(),
+                query: searchTerm.trim(),
+                tags: tagSearch,
+                tags_mode: extraFilters?.tagsMode || 'all',
+                ...(extraFilters?.near ? {
+                    lat: extraFilters.near.lat,
+                    lon: extraFilters.near.lon,
+                    radius_km: extraFilters.radiusKm,
+                } : {}),
+            }, { withCredentials: true });
+            alert('Поиск сохранён. Уведомления о новых постах появятся в разделе «События»');
+        } catch (error: any) {
+            alert(error.response?.data?.error || 'Не удалось сохранить поиск');
+        } finally {
+            setSavingSearch(false);
+        }
+    };
+
     const handleViewOnMap = () => {
         navigate('/map/all');
     };
@@ -158,6 +243,82 @@ const RightFilters: React.FC<RightFiltersProps> = ({
                 </div>
             </div>
 
+            {/* Дополнительные фильтры */}
+            {extraFilters && setExtraFilters && (
+                <div className="right-sort-block">
+                    <span className="tags-title">Дополнительно</span>
+                    <div className="tags-line"></div>
+
+                    <div className="right-sort-options">
+                        <label className="right-sort-option">
+                            <input
+                                type="checkbox"
+                                checked={extraFilters.tagsMode === 'any'}
+                                onChange={(e) => updateExtraFilters({ tagsMode: e.target.checked ? 'any' : 'all' })}
+                                className="right-sort-radio"
+                            />
+                            <span className="right-sort-label">Любой из тегов</span>
+                        </label>
+
+                        <label className="right-sort-option">
+                            <input
+                                type="checkbox"
+                                checked={extraFilters.hasPhotos}
+                                onChange={(e) => updateExtraFilters({ hasPhotos: e.target.checked })}
+                                className="right-sort-radio"
+                            />
+                            <span className="right-sort-label">Только с фото</span>
+                        </label>
+
+                        <label className="right-sort-option">
+                            <span className="right-sort-label">Лайков от</span>
+                            <select
+                                value={extraFilters.minLikes}
+                                onChange={(e) => updateExtraFilters({ minLikes: Number(e.target.value) })}
+                                className="right-filter-select"
+                            >
+                                {[0, 5, 10, 25, 50, 100].map(value => (
+                                    <option key={value} value={value}>{value}</option>
+                                ))}
+                            </select>
+                        </label>
+
+                        <label className="right-sort-option">
+                            <input
+                                type="checkbox"
+                                checked={extraFilters.near !== null}
+                                disabled={locating}
+                                onChange={toggleNearMe}
+                                className="right-sort-radio"
+                            />
+                            <span className="right-sort-label">{locating ? 'Определяем место...' : 'Рядом со мной'}</span>
+                            <select
+                                value={extraFilters.radiusKm}
+                                onChange={(e) => updateExtraFilters({ radiusKm: Number(e.target.value) })}
+                                className="right-filter-select"
+                            >
+                                {[10, 25, 50, 100, 250].map(value => (
+                                    <option key={value} value={value}>{value} км</option>
+                                ))}
+                            </select>
+                        </label>
+                        {locationError && <div className="top-users-error">{locationError}</div>}
+                    </div>
+
+                    {isLoggedIn && (
+                        <button
+                            onClick={handleSaveSearch}
+                            disabled={savingSearch || (!searchTerm.trim() && !tagSearch.trim() && !extraFilters.near)}
+                            className="right-map-button"
+                            style={{ marginTop: '16px' }}
+                        >
+                            <FaBell size={16} />
+                            {savingSearch ? 'Сохраняем...' : 'Сохранить поиск'}
+                        </button>
+                    )}
+                </div>
+            )}
+
             {/* Кнопка "Посмотреть на карте" - оптимизированные отступы */}
             <div className="right-map-button-wrapper">
                 <button
@@ -10,6 +10,7 @@ import { FaSearch, FaSignOutAlt, FaSignInAlt, FaUserPlus, FaHome, FaHeart, FaBoo
 
 const Sidebar: React.FC = () => {
     const [invitesCount, setInvitesCount] = useState<number>(0);
+    const [unreadNotifications, setUnreadNotifications] = useState<number>(0);
     const [isLoading, setIsLoading] = useState<boolean>(false);
     
     const navigate = useNavigate();
@@ -61,6 +62,40 @@ const Sidebar: React.FC = () => {
         }
     }, [isLoggedIn]);
     
+    // Непрочитанные уведомления: счётчик при входе, дальше - по личному потоку
+    useEffect(() => {
+        if (!isLoggedIn) {
+            setUnreadNotifications(0);
+            return;
+        }
+
+        axios.get('/api/notifications?unread=true&limit=1', { withCredentials: true })
+            .then(response => setUnreadNotifications(response.data.unread_count || 0))
+            .catch(error => console.error('Ошибка загрузки уведомлений:', error));
+
+        const eventSource = new EventSource('/api/notifications/stream', { withCredentials: true });
+        eventSource.onmessage = (event) => {
+            try {
+                const message = JSON.parse(event.data);
+                if (message.type === 'SAVED_SEARCH_MATCH') {
+                    setUnreadNotifications(prev => prev + 1);
+                }
+            } catch (error) {
+                console.error('Error parsing SSE message:', error);
+            }
+        };
+        return () => eventSource.close();
+    }, [isLoggedIn]);
+
+    // Счётчик сбрасывается, когда пользователь открывает события
+    useEffect(() => {
+        if (location.pathname === '/invites') {
+            setUnreadNotifications(0);
+        }
+    }, [location.pathname]);
+
+    const eventsCount = invitesCount + unreadNotifications;
+
     const handleLogout = async () => {
         await logout();
         navigate('/login');
@@ -158,7 +193,7 @@ const Sidebar: React.FC = () => {
                     >
                         <span style={{ fontSize: '20px' }}><FaBell /></span>
                         <span style={{ flex: 1 }}>События</span>
-                        {invitesCount > 0 && (
+                        {eventsCount > 0 && (
                             <span style={{
                                 backgroundColor: '#e74c3c',
                                 color: 'white',
@@ -169,7 +204,7 @@ const Sidebar: React.FC = () => {
                                 minWidth: '20px',
                                 textAlign: 'center'
                             }}>
-                                {invitesCount > 99 ? '99+' : invitesCount}
+                                {eventsCount > 99 ? '99+' : eventsCount}
                             </span>
                         )}
                         {isLoading && (
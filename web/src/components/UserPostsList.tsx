@@ -35,6 +35,13 @@ interface SSEMessage {
     data: any;
 }
 
+// mergeStatuses обновляет отметки (избранное, лайки) только для постов из postIds
+const mergeStatuses = (prev: Set<number>, postIds: number[], marked: Set<number>) => {
+    const next = new Set(prev);
+    postIds.forEach(id => marked.has(id) ? next.add(id) : next.delete(id));
+    return next;
+};
+
 const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
     // Состояния
     const [posts, setPosts] = useState<PostData[]>([]);
@@ -48,6 +55,9 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
     const [reportPostId, setReportPostId] = useState<number | null>(null);
     const [sseConnected, setSseConnected] = useState(false);
     const [likesCounts, setLikesCounts] = useState<Map<number, number>>(new Map());
+    // Курсор следующей страницы; null - всё загружено
+    const [nextCursor, setNextCursor] = useState<string | null>(null);
+    const [loadingMore, setLoadingMore] = useState(false);
 
     // Refs
     const eventSourceRef = useRef<EventSource | null>(null);
@@ -95,7 +105,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
                     if (isFav) favouriteIds.add(parseInt(id));
                 });
                 
-                setFavourites(favouriteIds);
+                setFavourites(prev => mergeStatuses(prev, postIds, favouriteIds));
             } else {
                 // Для небольшого количества постов - по одному
                 const favouritePromises = postIds.map(postId =>
@@ -112,7 +122,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
                         .map((result, index) => postIds[index])
                 );
 
-                setFavourites(favouriteIds);
+                setFavourites(prev => mergeStatuses(prev, postIds, favouriteIds));
             }
         } catch (err) {
             console.error("Ошибка при загрузке статуса избранного:", err);
@@ -145,7 +155,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
                 }
             }
             
-            setLikes(likedIds);
+            setLikes(prev => mergeStatuses(prev, postIds, likedIds));
         } catch (err) {
             console.error("Ошибка при загрузке статуса лайков:", err);
             setLikes(new Set<number>());
@@ -171,7 +181,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
                 newLikesCounts.set(postIds[index], result.data.likes_count || 0);
             });
 
-            setLikesCounts(newLikesCounts);
+            setLikesCounts(prev => new Map([...prev, ...newLikesCounts]));
 
             setPosts(prev => prev.map(post => ({
                 ...post,
@@ -182,9 +192,13 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
         }
     }, []);
 
-    // Загрузка постов пользователя
-    const fetchPosts = useCallback(async () => {
-        setLoading(true);
+    // Загрузка постов пользователя; с cursor - следующая страница к уже показанным
+    const fetchPosts = useCallback(async (cursor?: string) => {
+        if (cursor) {
+            setLoadingMore(true);
+        } else {
+            setLoading(true);
+        }
         setError('');
 
         try {
@@ -199,17 +213,26 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
             }
 
             const response = await axios.get(url, {
+                params: cursor ? { cursor } : undefined,
                 withCredentials: true,
                 timeout: 10000 // 10 секунд таймаут
             });
 
             console.log('Posts loaded:', response.data);
-            const postsData = (response.data || []).map((post: PostData) => ({
+            const postsData = (response.data?.posts || []).map((post: PostData) => ({
                 ...post,
                 likes_count: post.likes_count || 0
             }));
             
-            setPosts(postsData);
+            if (cursor) {
+                setPosts(prev => {
+                    const known = new Set(prev.map(post => post.id));
+                    return [...prev, ...postsData.filter((post: PostData) => !known.has(post.id))];
+                });
+            } else {
+                setPosts(postsData);
+            }
+            setNextCursor(response.data?.next_cursor || null);
 
             // Загружаем статусы для авторизованных пользователей
             if (isLoggedIn && postsData.length > 0) {
@@ -242,6 +265,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
             }
         } finally {
             setLoading(false);
+            setLoadingMore(false);
         }
     }, [effectiveUserId, isLoggedIn, loadFavouritesStatus, loadLikesStatus, loadLikesCounts]);
 
@@ -622,7 +646,7 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
         return (
             <div className="posts-feed-error">
                 <p>{error}</p>
-                <button onClick={fetchPosts} className="retry-button">
+                <button onClick={() => fetchPosts()} className="retry-button">
                     Попробовать снова
                 </button>
             </div>
@@ -735,6 +759,14 @@ const UserPostsList: React.FC<UserPostsListProps> = ({ targetUserId }) => {
                 ))}
             </div>
 
+            {nextCursor && (
+                <div className="posts-load-more">
+                    <button onClick={() => fetchPosts(nextCursor)} className="load-more-button" disabled={loadingMore}>
+                        {loadingMore ? 'Загрузка...' : 'Показать ещё'}
+                    </button>
+                </div>
+            )}
+
             <ReportModal
                 isOpen={isReportModalOpen}
                 onClose={() => {
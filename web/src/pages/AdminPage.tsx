@@ -80,8 +80,18 @@ const AdminPage: React.FC = () => {
     const fetchAllUsers = async () => {
         try {
             setUsersLoading(true);
-            const response = await axios.get('/api/admin/users', { withCredentials: true });
-            setAllUsers(response.data || []);
+            // Список отдаётся страницами - загружаем все
+            const loaded: User[] = [];
+            let cursor: string | null = null;
+            do {
+                const response = await axios.get('/api/admin/users', {
+                    params: { limit: 100, ...(cursor ? { cursor } : {}) },
+                    withCredentials: true
+                });
+                loaded.push(...(response.data.users || []));
+                cursor = response.data.next_cursor || null;
+            } while (cursor);
+            setAllUsers(loaded);
         } catch (err) {
             console.error('Ошибка загрузки пользователей:', err);
             setError('Не удалось загрузить список пользователей');
@@ -8,9 +8,10 @@ import { FaFileAlt, FaComment, FaEye, FaEyeSlash } from 'react-icons/fa';
 
 interface Complaint {
     id: string;
-    type: 'POST' | 'COMMENT';
+    type: 'POST' | 'COMMENT' | 'DUPLICATE' | 'REVIEW';
     post_id?: number;
     comment_id?: number;
+    review_id?: number;
     post_title: string;
     comment_content?: string;
     author: string;
@@ -19,6 +20,9 @@ interface Complaint {
     created_at: string;
     complaint_count: number;
     is_approved: boolean;
+    // Автоматическая отметка о дубле: сходство и более ранние посты других авторов
+    similarity?: number;
+    matched_posts?: { id: number; title: string; author: string }[];
 }
 
 interface UserWithComplaints {
@@ -88,9 +92,13 @@ const ModeratorPage: React.FC = () => {
         }
     }, [activeTab]);
 
-    const handleStatusChange = async (complaintId: string, newStatus: Complaint['status']) => {
+    const handleStatusChange = async (complaint: Complaint, newStatus: Complaint['status']) => {
+        // У автоматических отметок о дублях своя нумерация и свой адрес
+        const url = complaint.type === 'DUPLICATE'
+            ? `/api/mod/duplicates/${complaint.id}/status`
+            : `/api/mod/complaints/${complaint.id}/status`;
         try {
-            await axios.put(`/api/mod/complaints/${complaintId}/status`, { status: newStatus }, { withCredentials: true });
+            await axios.put(url, { status: newStatus }, { withCredentials: true });
             await fetchComplaints();
         } catch (err) {
             console.error('Ошибка обновления статуса жалобы:', err);
@@ -129,6 +137,23 @@ const ModeratorPage: React.FC = () => {
         }
     };
 
+    const toggleReviewVisibility = async (reviewId: number, currentStatus: boolean) => {
+        const newStatus = !currentStatus;
+        const action = newStatus ? 'показан' : 'скрыт';
+        
+        try {
+            setVisibilityLoading(prev => ({ ...prev, [`review_${reviewId}`]: true }));
+            await axios.put(`/api/mod/reviews/${reviewId}/visibility`, { is_approved: newStatus }, { withCredentials: true });
+            await fetchComplaints();
+            alert(`Отзыв успешно ${action}`);
+        } catch (err: any) {
+            console.error('Ошибка изменения видимости отзыва:', err);
+            alert(err.response?.data?.error || 'Не удалось изменить видимость отзыва');
+        } finally {
+            setVisibilityLoading(prev => ({ ...prev, [`review_${reviewId}`]: false }));
+        }
+    };
+
     const blockUser = async (userId: number, username: string) => {
         if (!window.confirm(`Вы уверены, что хотите заблокировать пользователя "${username}"?`)) return;
         
@@ -162,7 +187,7 @@ const ModeratorPage: React.FC = () => {
     };
 
     const handleComplaintClick = (complaint: Complaint) => {
-        if (complaint.type === 'POST' && complaint.post_id) {
+        if (complaint.type !== 'COMMENT' && complaint.post_id) {
             navigate(`/post/${complaint.post_id}`);
         } else if (complaint.type === 'COMMENT' && complaint.comment_id && complaint.post_id) {
             navigate(`/post/${complaint.post_id}#comment-${complaint.comment_id}`);
@@ -206,19 +231,37 @@ const ModeratorPage: React.FC = () => {
                     </thead>
                     <tbody>
                         {complaints.map((complaint) => (
-                            <tr key={complaint.id} className="complaint-row" onClick={() => handleComplaintClick(complaint)}>
+                            <tr key={`${complaint.type}-${complaint.id}`} className="complaint-row" onClick={() => handleComplaintClick(complaint)}>
                                 <td className="type-cell text-center">
                                     <div className="content-type-badge">
-                                        {complaint.type === 'POST' ? <FaFileAlt size={14} /> : <FaComment size={14} />}
-                                        <span className="content-type-text">{complaint.type === 'POST' ? 'Пост' : 'Комментарий'}</span>
+                                        {complaint.type !== 'COMMENT' ? <FaFileAlt size={14} /> : <FaComment size={14} />}
+                                        <span className="content-type-text">
+                                            {complaint.type === 'POST' ? 'Пост' : complaint.type === 'DUPLICATE' ? 'Дубль' : complaint.type === 'REVIEW' ? 'Отзыв' : 'Комментарий'}
+                                        </span>
                                     </div>
                                 </td>
                                 <td className="content-cell">
                                     <div className="content-preview">
                                         <strong className="content-title">
-                                            {complaint.type === 'POST' ? complaint.post_title : 'Комментарий'}
+                                            {complaint.type !== 'COMMENT' ? complaint.post_title : 'Комментарий'}
                                         </strong>
-                                        {complaint.type === 'COMMENT' && complaint.comment_content && (
+                                        {complaint.type === 'DUPLICATE' && complaint.matched_posts && (
+                                            <div className="comment-content-preview">
+                                                Совпадает ({Math.round((complaint.similarity || 0) * 100)}%):{' '}
+                                                {complaint.matched_posts.map((p, i) => (
+                                                    <span key={p.id}>
+                                                        {i > 0 && ', '}
+                                                        <a
+                                                            href={`/post/${p.id}`}
+                                                            onClick={(e) => { e.preventDefault(); e.stopPropagation(); navigate(`/post/${p.id}`); }}
+                                                        >
+                                                            {p.title}
+                                                        </a>{' '}({p.author})
+                                                    </span>
+                                                ))}
+                                            </div>
+                                        )}
+                                        {(complaint.type === 'COMMENT' || complaint.type === 'REVIEW') && complaint.comment_content && (
                                             <div className="comment-content-preview">
                                                 {complaint.comment_content.length > 50 ? `${complaint.comment_content.substring(0, 50)}...` : complaint.comment_content}
                                             </div>
@@ -240,7 +283,7 @@ const ModeratorPage: React.FC = () => {
                                 </td>
                                 <td className="text-center" onClick={(e) => e.stopPropagation()}>
                                     <div className="visibility-actions">
-                                        {complaint.type === 'POST' && complaint.post_id && (
+                                        {complaint.type !== 'COMMENT' && complaint.post_id && (
                                             <button 
                                                 className={`visibility-btn ${complaint.is_approved ? 'btn-visible' : 'btn-hidden'}`}
                                                 onClick={() => togglePostVisibility(complaint.post_id!, complaint.is_approved)}
@@ -249,6 +292,20 @@ const ModeratorPage: React.FC = () => {
                                                 {getVisibilityButtonText(complaint.is_approved)}
                                             </button>
                                         )}
+                                        {complaint.type === 'REVIEW' && complaint.review_id && (
+                                            <button 
+                                                className={`visibility-btn ${complaint.is_approved ? 'btn-visible' : 'btn-hidden'}`}
+                                                onClick={() => toggleReviewVisibility(complaint.review_id!, complaint.is_approved)}
+                                                disabled={visibilityLoading[`review_${complaint.review_id}`]}
+                                            >
+                                                {visibilityLoading[`review_${complaint.review_id}`] ? '...' : (
+                                                    <>
+                                                        {complaint.is_approved ? <FaEyeSlash size={12} /> : <FaEye size={12} />}
+                                                        {getVisibilityButtonText(complaint.is_approved)}
+                                                    </>
+                                                )}
+                                            </button>
+                                        )}
                                         {complaint.type === 'COMMENT' && complaint.comment_id && (
                                             <button 
                                                 className={`visibility-btn ${complaint.is_approved ? 'btn-visible' : 'btn-hidden'}`}
@@ -269,7 +326,7 @@ const ModeratorPage: React.FC = () => {
                                     <select 
                                         className="status-select"
                                         value={complaint.status}
-                                        onChange={(e) => handleStatusChange(complaint.id, e.target.value as Complaint['status'])}
+                                        onChange={(e) => handleStatusChange(complaint, e.target.value as Complaint['status'])}
                                     >
                                         <option value="NEW">Новая</option>
                                         <option value="PROCESSING">В работе</option>
@@ -36,6 +36,7 @@ const PostEditPage: React.FC = () => {
     const [tags, setTags] = useState('');
     const [loading, setLoading] = useState(true);
     const [isSaving, setIsSaving] = useState(false);
+    const [version, setVersion] = useState<number | null>(null);
 
     const [slides, setSlides] = useState<SlideData[]>([]);
     const [currentSlideIndex, setCurrentSlideIndex] = useState(0);
@@ -45,10 +46,11 @@ const PostEditPage: React.FC = () => {
     useEffect(() => {
         const fetchPostData = async () => {
             try {
-                const response = await axios.get(`/api/posts/${id}`, { withCredentials: true });
+                const response = await axios.get(`/api/posts/${id}?lang=original`, { withCredentials: true });
                 const data = response.data;
 
                 setTitle(data.title);
+                setVersion(data.version ?? null);
                 
                 // Устанавливаем данные о населенном пункте
                 if (data.settlement_id && data.settlement_name) {
@@ -192,7 +194,8 @@ const PostEditPage: React.FC = () => {
             settlement_name: settlementInput,
             tags: parsedTags,
             paragraphs,
-            photos
+            photos,
+            ...(version !== null ? { version } : {})
         };
 
         console.log('Updating post with data:', postData);
@@ -208,6 +211,11 @@ const PostEditPage: React.FC = () => {
             navigate(`/post/${id}`);
         } catch (error: any) {
             console.error('Ошибка обновления:', error);
+            if (error.response?.status === 409) {
+                const conflicts: string[] = error.response.data?.conflicts || [];
+                alert(`Пост уже изменил другой редактор${conflicts.length ? ` (поля: ${conflicts.join(', ')})` : ''}. Обновите страницу, чтобы увидеть актуальную версию.`);
+                return;
+            }
             const errorMessage = error.response?.data?.details || error.response?.data?.error || 'Ошибка при обновлении';
             alert(errorMessage);
         } finally {
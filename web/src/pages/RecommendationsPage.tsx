@@ -20,10 +20,37 @@ interface PostData {
     user_id: number;
     user_avatar: string;
     user_name: string;
+    reasons?: RecommendationReason[];
 }
 
+// Причина рекомендации из ленты "для вас"; первая - самая весомая
+interface RecommendationReason {
+    code: 'settlement_affinity' | 'followed_author' | 'preferred_tags' | 'fresh' | 'popular';
+    contribution: number;
+    settlement_id?: number;
+    author_id?: number;
+    tags?: string[];
+}
+
+const reasonLabel = (reason: RecommendationReason, post: PostData) => {
+    switch (reason.code) {
+        case 'settlement_affinity':
+            return `Вам нравятся посты о месте ${post.settlement_name}`;
+        case 'followed_author':
+            return `Вы подписаны на ${post.user_name}`;
+        case 'preferred_tags':
+            return reason.tags?.length ? `Ваши любимые теги: #${reason.tags.join(' #')}` : 'Похожие теги';
+        case 'fresh':
+            return 'Новый пост';
+        case 'popular':
+            return 'Популярное';
+        default:
+            return '';
+    }
+};
+
 const RecommendationsPage: React.FC = () => {
-    const [activeTab, setActiveTab] = useState<'geo' | 'follow'>('geo');
+    const [activeTab, setActiveTab] = useState<'for_you' | 'geo' | 'follow'>('for_you');
     const [posts, setPosts] = useState<PostData[]>([]);
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState('');
@@ -44,9 +71,11 @@ const RecommendationsPage: React.FC = () => {
         setLoading(true);
         setError('');
         try {
-            const url = activeTab === 'geo' 
-                ? '/api/recommendations/geo?limit=20'
-                : '/api/recommendations/follow?limit=20';
+            const url = activeTab === 'for_you'
+                ? '/api/recommendations/for-you?limit=20'
+                : activeTab === 'geo'
+                    ? '/api/recommendations/geo?limit=20'
+                    : '/api/recommendations/follow?limit=20';
             const response = await axios.get(url, { withCredentials: true });
             setPosts(response.data.posts || []);
             
@@ -186,6 +215,9 @@ const RecommendationsPage: React.FC = () => {
                 <div className="rec-header">
                     <h1>Рекомендации</h1>
                     <div className="rec-tabs">
+                        <button className={`rec-tab ${activeTab === 'for_you' ? 'active' : ''}`} onClick={() => setActiveTab('for_you')}>
+                            Для вас
+                        </button>
                         <button className={`rec-tab ${activeTab === 'geo' ? 'active' : ''}`} onClick={() => setActiveTab('geo')}>
                             Похожие места
                         </button>
@@ -199,7 +231,8 @@ const RecommendationsPage: React.FC = () => {
                     <div className="rec-error"><p>{error}</p><button onClick={loadRecommendations}>Повторить</button></div>
                 ) : posts.length === 0 ? (
                     <div className="rec-empty">
-                        <p>{activeTab === 'geo' ? 'Нет рекомендаций по местам' : 'Нет рекомендаций от подписок'}</p>
+                        <p>{activeTab === 'for_you' ? 'Пока нечего посоветовать' : activeTab === 'geo' ? 'Нет рекомендаций по местам' : 'Нет рекомендаций от подписок'}</p>
+                        {activeTab === 'for_you' && <p className="hint">Лайкайте посты и подписывайтесь на авторов - лента подстроится под вас</p>}
                         {activeTab === 'geo' && <p className="hint">Лайкайте посты, чтобы получать персональные рекомендации</p>}
                         {activeTab === 'follow' && <p className="hint">Подпишитесь на авторов, чтобы видеть их посты</p>}
                     </div>
@@ -222,6 +255,12 @@ const RecommendationsPage: React.FC = () => {
                                     <span className="post-user-name">{post.user_name}</span>
                                 </div>
 
+                                {post.reasons && post.reasons.length > 0 && (
+                                    <div className="rec-reason" title={post.reasons.map(r => reasonLabel(r, post)).join('\n')}>
+                                        {reasonLabel(post.reasons[0], post)}
+                                    </div>
+                                )}
+
                                 <div className="post-header-row-new">
                                     <span className="post-title-new">{post.title}</span>
                                     <span className="post-date-new">{formatDate(post.created_at)}</span>
@@ -25,8 +25,8 @@ export interface CreateCommentRequest {
 export interface CommentsResponse {
   comments: Comment[];
   total: number;
-  page: number;
   limit: number;
   has_more: boolean;
+  next_cursor: string | null;
 }
 